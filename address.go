@@ -0,0 +1,197 @@
+package mapbox
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Address is a geocoding result in the form applications usually store it
+type Address struct {
+	Number      string
+	Street      string
+	Unit        string // the secondary address, e.g. "Apt 4", see Feature.Unit
+	City        string
+	Region      string
+	RegionCode  string // e.g. "CA", the region short code without its country prefix
+	Postcode    string
+	Country     string
+	CountryCode string // ISO 3166-1 alpha-2, upper case, e.g. "US"
+	Coordinate  Coordinate
+	Accuracy    string // e.g. "rooftop", "interpolated"
+}
+
+// ToAddress maps the feature components into an Address, from the geocoding context or the Search Box context.
+// Returns false when the feature isn't an addressable result, see Feature.IsAddressable.
+func (f *Feature) ToAddress() (Address, bool) {
+	if f == nil || !f.IsAddressable() {
+		return Address{}, false
+	}
+
+	var address Address
+	address.Number, address.Street = f.StreetAddress()
+	address.Unit = f.Unit()
+	address.City, _ = f.City()
+	address.Region, _ = f.Region()
+	address.Postcode, _ = f.Postcode()
+	address.Country, _ = f.Country()
+	address.Coordinate, _ = f.Coordinate()
+
+	address.RegionCode, _, _ = f.RegionCode()
+	address.CountryCode = f.countryCode()
+	if f.Properties != nil {
+		address.Accuracy = f.Properties.Accuracy
+	}
+
+	return address, true
+}
+
+// countryCode returns the upper case ISO 3166-1 alpha-2 code of the feature country, from the Search Box context
+// or the geocoding country context, or the feature itself when it is a country. Empty when unknown.
+func (f *Feature) countryCode() string {
+	if f.Properties != nil {
+		if context := f.Properties.Context; context != nil && context.Country != nil && context.Country.CountryCode != "" {
+			return strings.ToUpper(context.Country.CountryCode)
+		}
+	}
+	code := f.contextShortCode(TypeCountry)
+	if code == "" && f.Properties != nil && f.Kind() == KindCountry {
+		code = f.Properties.ShortCode
+	}
+	return strings.ToUpper(code)
+}
+
+// RegionCode returns the ISO 3166-2 code of the feature region, short without its country prefix (e.g. "CA") and
+// full (e.g. "US-CA"), from the Search Box context or the geocoding region context, or the feature itself when it
+// is a region. Mapbox omits the codes of regions without an ISO 3166-2 subdivision, e.g. in some countries with no
+// first level divisions, and of features outside any region, such as oceans, in which case ok is false.
+func (f *Feature) RegionCode() (short, full string, ok bool) {
+	if f == nil {
+		return "", "", false
+	}
+	if f.Properties != nil && f.Properties.Context != nil && f.Properties.Context.Region != nil {
+		region := f.Properties.Context.Region
+		if region.RegionCode != "" || region.RegionCodeFull != "" {
+			short, full = region.RegionCode, region.RegionCodeFull
+			if short == "" {
+				short = regionSubdivision(full)
+			}
+			return short, full, true
+		}
+	}
+
+	full = f.contextShortCode(TypeRegion)
+	if full == "" && f.Properties != nil && f.Kind() == KindRegion {
+		full = f.Properties.ShortCode
+	}
+	if full == "" {
+		return "", "", false
+	}
+	return regionSubdivision(full), full, true
+}
+
+// regionSubdivision returns the subdivision of an ISO 3166-2 code, e.g. "CA" for "US-CA"
+func regionSubdivision(code string) string {
+	if i := strings.IndexByte(code, '-'); i >= 0 {
+		return code[i+1:]
+	}
+	return code
+}
+
+// contextShortCode returns the short code of the geocoding context of type t, e.g. "US-CA" for the region
+func (f *Feature) contextShortCode(t Type) string {
+	for _, context := range f.Context {
+		if context != nil && context.Type() == t {
+			return context.ShortCode
+		}
+	}
+	return ""
+}
+
+// postcodeFirstCountries write the postcode before the city and the house number after the street,
+// e.g. "Hauptstraße 5, 10115 Berlin, Germany"
+var postcodeFirstCountries = map[string]bool{
+	"AT": true, "BE": true, "CH": true, "DE": true, "DK": true, "ES": true, "FI": true,
+	"FR": true, "IT": true, "NL": true, "NO": true, "PL": true, "PT": true, "SE": true,
+}
+
+// ParseFullAddress splits a comma-separated full address, e.g. a Search Box full_address, into an Address.
+// It is a heuristic for results with a thin context, prefer Feature.ToAddress which uses the structured context.
+// countryCode (ISO 3166-1 alpha-2) selects the layout: "6005 Hidden Valley Road, Apt 4, Carlsbad, California
+// 92011, United States" by default as in the US, or "Hauptstraße 5, 10115 Berlin, Germany" for countries writing
+// the postcode first, e.g. "DE". Without a countryCode the postcode first layout is assumed when the part before
+// the country starts with the postcode. Components that can't be told apart are left empty.
+func ParseFullAddress(s, countryCode string) Address {
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	address := Address{CountryCode: strings.ToUpper(countryCode)}
+	if len(parts) < 2 {
+		return address
+	}
+
+	n := len(parts)
+	address.Country = parts[n-1]
+	postcodeFirst := postcodeFirstCountries[address.CountryCode]
+	if address.CountryCode == "" && n >= 3 {
+		_, postcode := splitPostcode(parts[n-2])
+		postcodeFirst = postcode != "" && strings.HasPrefix(parts[n-2], postcode+" ")
+	}
+	if postcodeFirst {
+		// street [number], [unit,] postcode city, country
+		address.City, address.Postcode = splitPostcode(parts[n-2])
+		if n >= 3 {
+			address.Street, address.Number = parseTrailingNumber(parts[0])
+		}
+		if n >= 4 {
+			address.Unit = strings.Join(parts[1:n-2], ", ")
+		}
+		return address
+	}
+
+	// number street, [unit,] city, region postcode, country
+	if n >= 3 {
+		address.Region, address.Postcode = splitPostcode(parts[n-2])
+		address.City = parts[n-3]
+	}
+	if n >= 4 {
+		address.Number, address.Street = parseStreetLine(parts[0])
+	}
+	if n >= 5 {
+		address.Unit = strings.Join(parts[1:n-3], ", ")
+	}
+	return address
+}
+
+// parseTrailingNumber splits "Hauptstraße 5" into the street and its trailing house number,
+// the last word is taken as the number when it contains a digit
+func parseTrailingNumber(line string) (street, number string) {
+	i := strings.LastIndexByte(line, ' ')
+	if i < 0 || !strings.ContainsAny(line[i+1:], "0123456789") {
+		return line, ""
+	}
+	return strings.TrimSpace(line[:i]), line[i+1:]
+}
+
+// NormalizeAddress returns a canonical form of an address for comparisons and cache keys, so "123 Main St." and
+// "123  main st" are equal: lower case, periods and apostrophes dropped ("St." is "st", "O'Brien" is "obrien"),
+// other punctuation and whitespace collapsed into single spaces ("12-14" is "12 14"). Letters of any script keep
+// their diacritics, "Müller" and "Muller" stay distinct since the difference can be significant.
+func NormalizeAddress(s string) string {
+	var b strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == '.' || r == '\'' || r == '’':
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			if separate && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			separate = false
+			b.WriteRune(r)
+		default:
+			separate = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package mapbox
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFeatureToAddress(t *testing.T) {
+	address, ok := decodeFeature(t, addressFeatureJSON).ToAddress()
+	if !ok {
+		t.Fatalf("expected an address")
+	}
+	expected := Address{
+		Number:      "6005",
+		Street:      "Hidden Valley Road",
+		City:        "Carlsbad",
+		Region:      "California",
+		RegionCode:  "CA",
+		Postcode:    "92011",
+		Country:     "United States",
+		CountryCode: "US",
+		Coordinate:  Coordinate{Lat: 33.1226, Lng: -117.31},
+		Accuracy:    "rooftop",
+	}
+	if address != expected {
+		t.Errorf("expected:\n%+v, got:\n%+v", expected, address)
+	}
+
+	var response SearchBoxForwardResponse
+	if err := json.Unmarshal([]byte(searchBoxForwardJSON), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	address, ok = response.Features[0].ToAddress()
+	if !ok {
+		t.Fatalf("expected a search box address")
+	}
+	expected = Address{
+		Number:      "6965",
+		Street:      "El Camino Real",
+		City:        "Carlsbad",
+		Region:      "California",
+		RegionCode:  "CA",
+		Postcode:    "92009",
+		Country:     "United States",
+		CountryCode: "US",
+		Coordinate:  Coordinate{Lat: 33.1227, Lng: -117.3101},
+	}
+	if address != expected {
+		t.Errorf("expected:\n%+v, got:\n%+v", expected, address)
+	}
+
+	place := decodeFeature(t, `{"id":"place.1","place_type":["place"],"text":"Carlsbad"}`)
+	if _, ok := place.ToAddress(); ok {
+		t.Errorf("expected a place not to be an address")
+	}
+}
+
+func TestFeatureRegionCode(t *testing.T) {
+	var response SearchBoxForwardResponse
+	if err := json.Unmarshal([]byte(searchBoxForwardJSON), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		feature     *Feature
+		short, full string
+		ok          bool
+	}{
+		{decodeFeature(t, addressFeatureJSON), "CA", "US-CA", true},
+		{response.Features[0], "CA", "US-CA", true},
+		{decodeFeature(t, `{"id":"region.1","place_type":["region"],"properties":{"short_code":"US-TX"}}`), "TX", "US-TX", true},
+		{&Feature{Properties: &Properties{Context: &SearchBoxContext{Region: &SearchBoxContextComponent{RegionCodeFull: "CA-QC"}}}}, "QC", "CA-QC", true},
+		{decodeFeature(t, `{"id":"place.1","place_type":["place"],"context":[{"id":"country.1","short_code":"mc"}]}`), "", "", false},
+	}
+
+	for _, test := range tests {
+		short, full, ok := test.feature.RegionCode()
+		if short != test.short || full != test.full || ok != test.ok {
+			t.Errorf("%v: expected %q %q %v, got %q %q %v", test.feature.ID, test.short, test.full, test.ok, short, full, ok)
+		}
+	}
+}
+
+func TestParseFullAddress(t *testing.T) {
+	tests := []struct {
+		address     string
+		countryCode string
+		expected    Address
+	}{
+		{
+			"6005 Hidden Valley Road, Apt 4, Carlsbad, California 92011, United States", "us",
+			Address{Number: "6005", Street: "Hidden Valley Road", Unit: "Apt 4", City: "Carlsbad", Region: "California", Postcode: "92011", Country: "United States", CountryCode: "US"},
+		},
+		{
+			"6965 El Camino Real, Carlsbad, California 92009, United States", "",
+			Address{Number: "6965", Street: "El Camino Real", City: "Carlsbad", Region: "California", Postcode: "92009", Country: "United States"},
+		},
+		{
+			"Carlsbad, California, United States", "US",
+			Address{City: "Carlsbad", Region: "California", Country: "United States", CountryCode: "US"},
+		},
+		{
+			"Hauptstraße 5, 10115 Berlin, Germany", "DE",
+			Address{Number: "5", Street: "Hauptstraße", City: "Berlin", Postcode: "10115", Country: "Germany", CountryCode: "DE"},
+		},
+		{
+			"Rue de Rivoli, 75001 Paris, France", "fr",
+			Address{Street: "Rue de Rivoli", City: "Paris", Postcode: "75001", Country: "France", CountryCode: "FR"},
+		},
+		{
+			"Hauptstraße 5, 10115 Berlin, Germany", "",
+			Address{Number: "5", Street: "Hauptstraße", City: "Berlin", Postcode: "10115", Country: "Germany"},
+		},
+		{"United States", "US", Address{CountryCode: "US"}},
+	}
+
+	for _, test := range tests {
+		if address := ParseFullAddress(test.address, test.countryCode); address != test.expected {
+			t.Errorf("%q: expected:\n%+v, got:\n%+v", test.address, test.expected, address)
+		}
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"123 Main St.", "123 main st"},
+		{"  123   main st ", "123 main st"},
+		{"12-14 O'Brien Ave, Apt #4", "12 14 obrien ave apt 4"},
+		{"Hauptstraße 5, 10115 BERLIN", "hauptstraße 5 10115 berlin"},
+		{"Müller-Weg 3", "müller weg 3"},
+		{"東京都港区", "東京都港区"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if normalized := NormalizeAddress(test.address); normalized != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.address, test.expected, normalized)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package mapbox
+
+// MapboxAttribution is the attribution Mapbox requires wherever its data is displayed, for the responses
+// without an attribution of their own, see https://docs.mapbox.com/help/getting-started/attribution/
+const MapboxAttribution = "© Mapbox © OpenStreetMap"
+
+// RequiredAttribution returns the attribution to display with the results, the response Attribution notice,
+// or MapboxAttribution when it has none
+func (r *ForwardGeocodeResponse) RequiredAttribution() string {
+	return attribution(r.Attribution)
+}
+
+// RequiredAttribution returns the attribution to display with the results, see ForwardGeocodeResponse.RequiredAttribution
+func (r *ReverseGeocodeResponse) RequiredAttribution() string {
+	return attribution(r.Attribution)
+}
+
+// RequiredAttribution returns the attribution to display with the results, see ForwardGeocodeResponse.RequiredAttribution
+func (r *SearchBoxForwardResponse) RequiredAttribution() string {
+	return attribution(r.Attribution)
+}
+
+// RequiredAttribution returns MapboxAttribution, Directions responses carry no attribution
+func (r *DirectionsResponse) RequiredAttribution() string {
+	return MapboxAttribution
+}
+
+// RequiredAttribution returns MapboxAttribution, Matrix responses carry no attribution
+func (r *DirectionsMatrixResponse) RequiredAttribution() string {
+	return MapboxAttribution
+}
+
+func attribution(notice string) string {
+	if notice == "" {
+		return MapboxAttribution
+	}
+	return notice
+}
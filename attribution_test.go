@@ -0,0 +1,30 @@
+package mapbox
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRequiredAttribution(t *testing.T) {
+	notice := "NOTICE: © 2024 Mapbox and its suppliers. All rights reserved."
+	var forward ForwardGeocodeResponse
+	if err := json.Unmarshal([]byte(`{"type":"FeatureCollection","features":[],"attribution":"`+notice+`"}`), &forward); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		response interface{ RequiredAttribution() string }
+		expected string
+	}{
+		{&forward, notice},
+		{&ReverseGeocodeResponse{}, MapboxAttribution},
+		{&SearchBoxForwardResponse{Attribution: notice}, notice},
+		{&DirectionsResponse{}, MapboxAttribution},
+		{&DirectionsMatrixResponse{}, MapboxAttribution},
+	}
+	for _, test := range tests {
+		if attribution := test.response.RequiredAttribution(); attribution != test.expected {
+			t.Errorf("%T: expected %q, got %q", test.response, test.expected, attribution)
+		}
+	}
+}
@@ -1,12 +1,170 @@
 package mapbox
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
 
 type BoundingBox struct {
 	Min Coordinate
 	Max Coordinate
 }
 
+// BoundingBoxFromWebMercator converts an EPSG:3857 Web Mercator extent in meters, e.g. of a map tile, into a
+// BoundingBox for forward geocoding. Returns an error when the extent is outside the projection or inverted.
+func BoundingBoxFromWebMercator(minX, minY, maxX, maxY float64) (BoundingBox, error) {
+	if minX > maxX || minY > maxY {
+		return BoundingBox{}, fmt.Errorf("web mercator extent min %v,%v is beyond max %v,%v", minX, minY, maxX, maxY)
+	}
+	min, err := CoordinateFromWebMercator(minX, minY)
+	if err != nil {
+		return BoundingBox{}, err
+	}
+	max, err := CoordinateFromWebMercator(maxX, maxY)
+	if err != nil {
+		return BoundingBox{}, err
+	}
+	return BoundingBox{Min: min, Max: max}, nil
+}
+
+// query returns the box as "{minLng},{minLat},{maxLng},{maxLat}", with full precision and without exponent notation
 func (b BoundingBox) query() string {
-	return fmt.Sprintf("%v,%v,%v,%v", b.Min.Lng, b.Min.Lat, b.Max.Lng, b.Max.Lat)
+	return strings.Join([]string{
+		formatDegrees(b.Min.Lng),
+		formatDegrees(b.Min.Lat),
+		formatDegrees(b.Max.Lng),
+		formatDegrees(b.Max.Lat),
+	}, ",")
+}
+
+// Normalize wraps the longitudes into the [-180, 180] range, e.g. a box from 177 to 182 becomes 177 to -178
+func (b BoundingBox) Normalize() BoundingBox {
+	b.Min.Lng = wrapLongitude(b.Min.Lng)
+	b.Max.Lng = wrapLongitude(b.Max.Lng)
+	return b
+}
+
+// CrossesAntimeridian reports whether the box spans the 180° meridian, i.e. its normalized Min.Lng is east of Max.Lng
+func (b BoundingBox) CrossesAntimeridian() bool {
+	n := b.Normalize()
+	return n.Min.Lng > n.Max.Lng
+}
+
+// Split returns the box as one box on each side of the antimeridian when it crosses it, or the normalized box otherwise
+func (b BoundingBox) Split() []BoundingBox {
+	n := b.Normalize()
+	if n.Min.Lng <= n.Max.Lng {
+		return []BoundingBox{n}
+	}
+
+	return []BoundingBox{
+		{Min: n.Min, Max: Coordinate{Lat: n.Max.Lat, Lng: 180}},
+		{Min: Coordinate{Lat: n.Min.Lat, Lng: -180}, Max: n.Max},
+	}
+}
+
+// Contains reports whether c lies within the box, edges included, wrapping across the antimeridian when the box crosses it
+func (b BoundingBox) Contains(c Coordinate) bool {
+	if c.Lat < b.Min.Lat || c.Lat > b.Max.Lat {
+		return false
+	}
+	n, lng := b.Normalize(), wrapLongitude(c.Lng)
+	if n.Min.Lng <= n.Max.Lng {
+		return lng >= n.Min.Lng && lng <= n.Max.Lng
+	}
+	return lng >= n.Min.Lng || lng <= n.Max.Lng
+}
+
+// validate checks the box can be sent as a bbox parameter, which must not cross the antimeridian
+func (b BoundingBox) validate() error {
+	if b.Min.Lat > b.Max.Lat {
+		return fmt.Errorf("bbox min latitude %v is north of max latitude %v", b.Min.Lat, b.Max.Lat)
+	}
+	if b.CrossesAntimeridian() {
+		return fmt.Errorf("bbox crosses the antimeridian, use BoundingBox.Split and issue one request per box")
+	}
+	return nil
+}
+
+// Polygon returns the bounding box as closed counterclockwise rings of five coordinates.
+// A box crossing the antimeridian is split into two rings, one on each side.
+func (b BoundingBox) Polygon() [][]Coordinate {
+	boxes := b.Split()
+
+	rings := make([][]Coordinate, 0, len(boxes))
+	for _, box := range boxes {
+		rings = append(rings, ring(box.Min.Lng, box.Min.Lat, box.Max.Lng, box.Max.Lat))
+	}
+	return rings
+}
+
+// GeoJSON returns the bounding box as a GeoJSON Polygon, or a MultiPolygon when it crosses the antimeridian.
+// Returns nil when the box can't be encoded (NaN or infinite coordinates).
+func (b BoundingBox) GeoJSON() json.RawMessage {
+	rings := b.Polygon()
+
+	polygons := make([][][][]float64, 0, len(rings))
+	for _, r := range rings {
+		polygons = append(polygons, [][][]float64{Coordinates(r).positions()})
+	}
+
+	var geometry interface{}
+	if len(polygons) == 1 {
+		geometry = struct {
+			Type        string        `json:"type"`
+			Coordinates [][][]float64 `json:"coordinates"`
+		}{"Polygon", polygons[0]}
+	} else {
+		geometry = struct {
+			Type        string          `json:"type"`
+			Coordinates [][][][]float64 `json:"coordinates"`
+		}{"MultiPolygon", polygons}
+	}
+
+	data, err := json.Marshal(geometry)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// WKT returns the bounding box as a Well-Known Text POLYGON, or a MULTIPOLYGON when it crosses the antimeridian
+func (b BoundingBox) WKT() string {
+	rings := b.Polygon()
+
+	polygons := make([]string, 0, len(rings))
+	for _, r := range rings {
+		// rings always have five positions
+		positions, _ := wktPositions(Coordinates(r).positions())
+		polygons = append(polygons, "("+positions+")")
+	}
+
+	if len(polygons) == 1 {
+		return "POLYGON" + polygons[0]
+	}
+	return "MULTIPOLYGON(" + strings.Join(polygons, ",") + ")"
+}
+
+func ring(minLng, minLat, maxLng, maxLat float64) []Coordinate {
+	return []Coordinate{
+		{Lat: minLat, Lng: minLng},
+		{Lat: minLat, Lng: maxLng},
+		{Lat: maxLat, Lng: maxLng},
+		{Lat: maxLat, Lng: minLng},
+		{Lat: minLat, Lng: minLng},
+	}
+}
+
+func wrapLongitude(lng float64) float64 {
+	if lng >= -180 && lng <= 180 {
+		return lng
+	}
+
+	wrapped := math.Mod(lng+180, 360)
+	if wrapped < 0 {
+		wrapped += 360
+	}
+	return wrapped - 180
 }
@@ -0,0 +1,150 @@
+package mapbox
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoundingBoxGeoJSON(t *testing.T) {
+	tests := []struct {
+		bbox     BoundingBox
+		expected string
+		wkt      string
+	}{
+		{
+			BoundingBox{Min: Coordinate{Lat: 33.1, Lng: -117.4}, Max: Coordinate{Lat: 33.2, Lng: -117.3}},
+			`{"type":"Polygon","coordinates":[[[-117.4,33.1],[-117.3,33.1],[-117.3,33.2],[-117.4,33.2],[-117.4,33.1]]]}`,
+			"POLYGON((-117.4 33.1,-117.3 33.1,-117.3 33.2,-117.4 33.2,-117.4 33.1))",
+		},
+		// Fiji, crossing the antimeridian
+		{
+			BoundingBox{Min: Coordinate{Lat: -21, Lng: 177}, Max: Coordinate{Lat: -12.5, Lng: -178}},
+			`{"type":"MultiPolygon","coordinates":[` +
+				`[[[177,-21],[180,-21],[180,-12.5],[177,-12.5],[177,-21]]],` +
+				`[[[-180,-21],[-178,-21],[-178,-12.5],[-180,-12.5],[-180,-21]]]]}`,
+			"MULTIPOLYGON(((177 -21,180 -21,180 -12.5,177 -12.5,177 -21)),((-180 -21,-178 -21,-178 -12.5,-180 -12.5,-180 -21)))",
+		},
+	}
+
+	for _, test := range tests {
+		rings := test.bbox.Polygon()
+		for _, r := range rings {
+			if len(r) != 5 || r[0] != r[4] {
+				t.Errorf("expected closed ring of five coordinates, got %v", r)
+			}
+		}
+
+		actual := string(test.bbox.GeoJSON())
+		if actual != test.expected {
+			t.Errorf("expected:\n%s, got:\n%s", test.expected, actual)
+		}
+		if wkt := test.bbox.WKT(); wkt != test.wkt {
+			t.Errorf("expected:\n%s, got:\n%s", test.wkt, wkt)
+		}
+	}
+}
+
+func TestBoundingBoxAntimeridian(t *testing.T) {
+	fiji := BoundingBox{Min: Coordinate{Lat: -21, Lng: 177}, Max: Coordinate{Lat: -12.5, Lng: -178}}
+	fijiUnwrapped := BoundingBox{Min: Coordinate{Lat: -21, Lng: 177}, Max: Coordinate{Lat: -12.5, Lng: 182}}
+	carlsbad := BoundingBox{Min: Coordinate{Lat: 33.1, Lng: -117.4}, Max: Coordinate{Lat: 33.2, Lng: -117.3}}
+
+	if !fiji.CrossesAntimeridian() || !fijiUnwrapped.CrossesAntimeridian() || carlsbad.CrossesAntimeridian() {
+		t.Errorf("unexpected antimeridian detection")
+	}
+	if normalized := fijiUnwrapped.Normalize(); normalized != fiji {
+		t.Errorf("expected %v, got %v", fiji, normalized)
+	}
+
+	expected := []BoundingBox{
+		{Min: Coordinate{Lat: -21, Lng: 177}, Max: Coordinate{Lat: -12.5, Lng: 180}},
+		{Min: Coordinate{Lat: -21, Lng: -180}, Max: Coordinate{Lat: -12.5, Lng: -178}},
+	}
+	split := fijiUnwrapped.Split()
+	if len(split) != 2 || split[0] != expected[0] || split[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, split)
+	}
+	for _, box := range split {
+		if err := box.validate(); err != nil {
+			t.Errorf("expected split box %v to be valid, got %v", box, err)
+		}
+	}
+
+	if err := fiji.validate(); err == nil {
+		t.Errorf("expected antimeridian crossing box to be rejected")
+	}
+	if split := carlsbad.Split(); len(split) != 1 || split[0] != carlsbad {
+		t.Errorf("expected %v, got %v", carlsbad, split)
+	}
+}
+
+func TestBoundingBoxQuery(t *testing.T) {
+	tests := []struct {
+		bbox     BoundingBox
+		expected string
+	}{
+		{BoundingBox{Min: Coordinate{Lat: 33.121217, Lng: -117.310429}, Max: Coordinate{Lat: 33.124973, Lng: -117.305054}}, "-117.310429,33.121217,-117.305054,33.124973"},
+		// %v would switch to exponent notation for these
+		{BoundingBox{Min: Coordinate{Lat: 0.0000001, Lng: -0.00000123}, Max: Coordinate{Lat: 0.00001, Lng: 0.000002}}, "-0.00000123,0.0000001,0.000002,0.00001"},
+		{BoundingBox{Min: Coordinate{Lat: -90, Lng: -180}, Max: Coordinate{Lat: 90, Lng: 180}}, "-180,-90,180,90"},
+	}
+
+	for _, test := range tests {
+		if actual := test.bbox.query(); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestBoundingBoxContains(t *testing.T) {
+	box := BoundingBox{Min: Coordinate{Lat: 33, Lng: -118}, Max: Coordinate{Lat: 34, Lng: -117}}
+	antimeridian := BoundingBox{Min: Coordinate{Lat: -20, Lng: 177}, Max: Coordinate{Lat: -10, Lng: 182}}
+
+	tests := []struct {
+		box      BoundingBox
+		c        Coordinate
+		expected bool
+	}{
+		{box, Coordinate{Lat: 33.5, Lng: -117.5}, true},
+		{box, Coordinate{Lat: 33, Lng: -118}, true},
+		{box, Coordinate{Lat: 34.5, Lng: -117.5}, false},
+		{box, Coordinate{Lat: 33.5, Lng: -116}, false},
+		{antimeridian, Coordinate{Lat: -15, Lng: 179}, true},
+		{antimeridian, Coordinate{Lat: -15, Lng: -179}, true},
+		{antimeridian, Coordinate{Lat: -15, Lng: 0}, false},
+	}
+
+	for _, test := range tests {
+		if contains := test.box.Contains(test.c); contains != test.expected {
+			t.Errorf("%v in %v: expected %v, got %v", test.c, test.box, test.expected, contains)
+		}
+	}
+}
+
+func TestBoundingBoxFromWebMercator(t *testing.T) {
+	box, err := BoundingBoxFromWebMercator(-webMercatorExtent, -webMercatorExtent, webMercatorExtent, webMercatorExtent)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := BoundingBox{Min: Coordinate{Lat: -85.0511287798066, Lng: -180}, Max: Coordinate{Lat: 85.0511287798066, Lng: 180}}
+	if math.Abs(box.Min.Lat-expected.Min.Lat) > 1e-9 || math.Abs(box.Max.Lat-expected.Max.Lat) > 1e-9 ||
+		math.Abs(box.Min.Lng-expected.Min.Lng) > 1e-9 || math.Abs(box.Max.Lng-expected.Max.Lng) > 1e-9 {
+		t.Errorf("expected %+v, got %+v", expected, box)
+	}
+
+	// tile 0/0 of zoom 1, the north-west quarter
+	box, err = BoundingBoxFromWebMercator(-webMercatorExtent, 0, 0, webMercatorExtent)
+	if err != nil || box.Min.Lat != 0 || box.Min.Lng != -180 || box.Max.Lng != 0 {
+		t.Errorf("unexpected quarter %+v, %v", box, err)
+	}
+
+	for _, extent := range [][4]float64{
+		{0, 0, 2e7, 2.1e7},
+		{1, 0, 0, 1},
+		{math.NaN(), 0, 1, 1},
+	} {
+		if _, err := BoundingBoxFromWebMercator(extent[0], extent[1], extent[2], extent[3]); err == nil {
+			t.Errorf("expected an error for %v", extent)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package mapbox
+
+// ForwardGeocodeBuilder builds a ForwardGeocodeRequest fluently, e.g.
+//
+//	req, err := NewForwardGeocode("coffee").WithCountry("us").WithProximity(c).WithLimit(5).WithTypes(TypePOI).Build()
+type ForwardGeocodeBuilder struct {
+	req ForwardGeocodeRequest
+}
+
+// NewForwardGeocode starts a forward geocoding request for searchText on EndpointPlaces
+func NewForwardGeocode(searchText string) *ForwardGeocodeBuilder {
+	return &ForwardGeocodeBuilder{req: ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: searchText}}
+}
+
+func (b *ForwardGeocodeBuilder) WithEndpoint(endpoint Endpoint) *ForwardGeocodeBuilder {
+	b.req.Endpoint = endpoint
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithAutocomplete(autocomplete bool) *ForwardGeocodeBuilder {
+	b.req.Autocomplete = autocomplete
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithBBox(bbox BoundingBox) *ForwardGeocodeBuilder {
+	b.req.BBox = bbox
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithCountry(country string) *ForwardGeocodeBuilder {
+	b.req.Country = country
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithFuzzyMatch(fuzzyMatch bool) *ForwardGeocodeBuilder {
+	b.req.FuzzyMatch = fuzzyMatch
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithLanguage(language string) *ForwardGeocodeBuilder {
+	b.req.Language = language
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithLimit(limit int) *ForwardGeocodeBuilder {
+	b.req.Limit = limit
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithProximity(proximity Coordinate) *ForwardGeocodeBuilder {
+	b.req.Proximity = proximity
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithRouting(routing bool) *ForwardGeocodeBuilder {
+	b.req.Routing = routing
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithTypes(types ...Type) *ForwardGeocodeBuilder {
+	b.req.Types = types
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithExcludeTypes(types ...Type) *ForwardGeocodeBuilder {
+	b.req.ExcludeTypes = types
+	return b
+}
+
+func (b *ForwardGeocodeBuilder) WithFallbackWithoutBBox(fallback bool) *ForwardGeocodeBuilder {
+	b.req.FallbackWithoutBBox = fallback
+	return b
+}
+
+// Build validates and returns a copy of the request, the builder can be reused
+func (b *ForwardGeocodeBuilder) Build() (*ForwardGeocodeRequest, error) {
+	req := b.req
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+//////////////////////////////////////////////////////////////////
+
+// ReverseGeocodeBuilder builds a ReverseGeocodeRequest fluently
+type ReverseGeocodeBuilder struct {
+	req ReverseGeocodeRequest
+}
+
+// NewReverseGeocode starts a reverse geocoding request for coordinate on EndpointPlaces
+func NewReverseGeocode(coordinate Coordinate) *ReverseGeocodeBuilder {
+	return &ReverseGeocodeBuilder{req: ReverseGeocodeRequest{Endpoint: EndpointPlaces, Coordinates: Coordinates{coordinate}}}
+}
+
+func (b *ReverseGeocodeBuilder) WithEndpoint(endpoint Endpoint) *ReverseGeocodeBuilder {
+	b.req.Endpoint = endpoint
+	return b
+}
+
+func (b *ReverseGeocodeBuilder) WithCountry(country string) *ReverseGeocodeBuilder {
+	b.req.Country = country
+	return b
+}
+
+func (b *ReverseGeocodeBuilder) WithLanguage(language string) *ReverseGeocodeBuilder {
+	b.req.Language = language
+	return b
+}
+
+func (b *ReverseGeocodeBuilder) WithLimit(limit int) *ReverseGeocodeBuilder {
+	b.req.Limit = limit
+	return b
+}
+
+func (b *ReverseGeocodeBuilder) WithReverseMode(mode ReverseMode) *ReverseGeocodeBuilder {
+	b.req.ReverseMode = mode
+	return b
+}
+
+func (b *ReverseGeocodeBuilder) WithRouting(routing bool) *ReverseGeocodeBuilder {
+	b.req.Routing = routing
+	return b
+}
+
+func (b *ReverseGeocodeBuilder) WithTypes(types ...Type) *ReverseGeocodeBuilder {
+	b.req.Types = types
+	return b
+}
+
+func (b *ReverseGeocodeBuilder) WithExcludeTypes(types ...Type) *ReverseGeocodeBuilder {
+	b.req.ExcludeTypes = types
+	return b
+}
+
+// Build validates and returns a copy of the request, the builder can be reused
+func (b *ReverseGeocodeBuilder) Build() (*ReverseGeocodeRequest, error) {
+	req := b.req
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+//////////////////////////////////////////////////////////////////
+
+// DirectionsBuilder builds a DirectionsRequest fluently, e.g.
+//
+//	req, err := NewDirections(ProfileDriving, from, to).WithSteps(true).WithExcludes(ExcludeToll).Build()
+type DirectionsBuilder struct {
+	req DirectionsRequest
+}
+
+// NewDirections starts a directions request along coordinates
+func NewDirections(profile Profile, coordinates ...Coordinate) *DirectionsBuilder {
+	return &DirectionsBuilder{req: DirectionsRequest{Profile: profile, Coordinates: coordinates}}
+}
+
+func (b *DirectionsBuilder) WithAlternatives(alternatives bool) *DirectionsBuilder {
+	b.req.Alternatives = &alternatives
+	return b
+}
+
+func (b *DirectionsBuilder) WithAnnotations(annotations ...Annotation) *DirectionsBuilder {
+	b.req.Annotations = annotations
+	return b
+}
+
+func (b *DirectionsBuilder) WithExcludes(excludes ...Exclude) *DirectionsBuilder {
+	b.req.Excludes = excludes
+	return b
+}
+
+func (b *DirectionsBuilder) WithGeometries(geometries Geometries) *DirectionsBuilder {
+	b.req.Geometries = geometries
+	return b
+}
+
+func (b *DirectionsBuilder) WithOverview(overview Overview) *DirectionsBuilder {
+	b.req.Overview = overview
+	return b
+}
+
+func (b *DirectionsBuilder) WithSteps(steps bool) *DirectionsBuilder {
+	b.req.Steps = &steps
+	return b
+}
+
+func (b *DirectionsBuilder) WithLanguage(language string) *DirectionsBuilder {
+	b.req.Language = language
+	return b
+}
+
+func (b *DirectionsBuilder) WithDepartAt(departAt DepartAt) *DirectionsBuilder {
+	b.req.DepartAt = departAt
+	return b
+}
+
+func (b *DirectionsBuilder) WithArriveBy(arriveBy ArriveBy) *DirectionsBuilder {
+	b.req.ArriveBy = arriveBy
+	return b
+}
+
+// Build validates and returns a copy of the request, the builder can be reused
+func (b *DirectionsBuilder) Build() (*DirectionsRequest, error) {
+	req := b.req
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
@@ -0,0 +1,59 @@
+package mapbox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestForwardGeocodeBuilder(t *testing.T) {
+	proximity := Coordinate{Lat: 33.1227, Lng: -117.3101}
+	req, err := NewForwardGeocode("coffee").WithCountry("us").WithProximity(proximity).WithLimit(5).WithTypes(TypePOI).Build()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "coffee",
+		Country:    "us",
+		Proximity:  proximity,
+		Limit:      5,
+		Types:      Types{TypePOI},
+	}
+	if !reflect.DeepEqual(req, expected) {
+		t.Errorf("expected:\n%+v, got:\n%+v", expected, req)
+	}
+
+	if _, err := NewForwardGeocode("").Build(); err == nil {
+		t.Errorf("expected a validation error")
+	}
+}
+
+func TestReverseGeocodeBuilder(t *testing.T) {
+	req, err := NewReverseGeocode(Coordinate{Lat: 33.1, Lng: -117.3}).WithLimit(3).WithTypes(TypeAddress).Build()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if req.Endpoint != EndpointPlaces || len(req.Coordinates) != 1 || req.Limit != 3 {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	if _, err := NewReverseGeocode(Coordinate{Lat: 33.1, Lng: -117.3}).WithLimit(3).Build(); err == nil {
+		t.Errorf("expected a limit without a single type to be rejected")
+	}
+}
+
+func TestDirectionsBuilder(t *testing.T) {
+	from, to := Coordinate{Lat: 33.1, Lng: -117.3}, Coordinate{Lat: 32.7, Lng: -117.2}
+	req, err := NewDirections(ProfileDriving, from, to).WithSteps(true).WithExcludes(ExcludeToll).Build()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if req.Steps == nil || !*req.Steps || len(req.Excludes) != 1 || len(req.Coordinates) != 2 {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	if _, err := NewDirections(ProfileDriving, from).Build(); err == nil {
+		t.Errorf("expected a single coordinate to be rejected")
+	}
+}
@@ -0,0 +1,175 @@
+package mapbox
+
+import (
+	"strings"
+)
+
+// Canonical Search Box POI category IDs, for SearchBoxForwardRequest.POICategories.
+// This is a subset of the published list, the Search Box list/category endpoint is authoritative.
+const (
+	CategoryFoodAndDrink      = Category("food_and_drink")
+	CategoryFood              = Category("food")
+	CategoryRestaurant        = Category("restaurant")
+	CategoryFastFood          = Category("fast_food")
+	CategoryCafe              = Category("cafe")
+	CategoryCoffee            = Category("coffee")
+	CategoryBakery            = Category("bakery")
+	CategoryBar               = Category("bar")
+	CategoryNightlife         = Category("nightlife")
+	CategoryHotel             = Category("hotel")
+	CategoryLodging           = Category("lodging")
+	CategoryGasStation        = Category("gas_station")
+	CategoryEVChargingStation = Category("ev_charging_station")
+	CategoryParkingLot        = Category("parking_lot")
+	CategoryCarRental         = Category("car_rental")
+	CategoryCarWash           = Category("car_wash")
+	CategoryShopping          = Category("shopping")
+	CategoryShoppingMall      = Category("shopping_mall")
+	CategoryGrocery           = Category("grocery")
+	CategorySupermarket       = Category("supermarket")
+	CategoryConvenienceStore  = Category("convenience_store")
+	CategoryClothingStore     = Category("clothing_store")
+	CategoryBookstore         = Category("bookstore")
+	CategoryPharmacy          = Category("pharmacy")
+	CategoryHospital          = Category("hospital")
+	CategoryDentist           = Category("dentist")
+	CategoryBank              = Category("bank")
+	CategoryATM               = Category("atm")
+	CategoryPostOffice        = Category("post_office")
+	CategoryLaundry           = Category("laundry")
+	CategorySchool            = Category("school")
+	CategoryUniversity        = Category("university")
+	CategoryLibrary           = Category("library")
+	CategoryMuseum            = Category("museum")
+	CategoryCinema            = Category("cinema")
+	CategoryPark              = Category("park")
+	CategoryTouristAttraction = Category("tourist_attraction")
+	CategoryAirport           = Category("airport")
+	CategoryTrainStation      = Category("train_station")
+	CategoryBusStation        = Category("bus_station")
+	CategoryPoliceStation     = Category("police_station")
+	CategoryFireStation       = Category("fire_station")
+)
+
+var categories = map[Category]struct{}{
+	CategoryFoodAndDrink:      {},
+	CategoryFood:              {},
+	CategoryRestaurant:        {},
+	CategoryFastFood:          {},
+	CategoryCafe:              {},
+	CategoryCoffee:            {},
+	CategoryBakery:            {},
+	CategoryBar:               {},
+	CategoryNightlife:         {},
+	CategoryHotel:             {},
+	CategoryLodging:           {},
+	CategoryGasStation:        {},
+	CategoryEVChargingStation: {},
+	CategoryParkingLot:        {},
+	CategoryCarRental:         {},
+	CategoryCarWash:           {},
+	CategoryShopping:          {},
+	CategoryShoppingMall:      {},
+	CategoryGrocery:           {},
+	CategorySupermarket:       {},
+	CategoryConvenienceStore:  {},
+	CategoryClothingStore:     {},
+	CategoryBookstore:         {},
+	CategoryPharmacy:          {},
+	CategoryHospital:          {},
+	CategoryDentist:           {},
+	CategoryBank:              {},
+	CategoryATM:               {},
+	CategoryPostOffice:        {},
+	CategoryLaundry:           {},
+	CategorySchool:            {},
+	CategoryUniversity:        {},
+	CategoryLibrary:           {},
+	CategoryMuseum:            {},
+	CategoryCinema:            {},
+	CategoryPark:              {},
+	CategoryTouristAttraction: {},
+	CategoryAirport:           {},
+	CategoryTrainStation:      {},
+	CategoryBusStation:        {},
+	CategoryPoliceStation:     {},
+	CategoryFireStation:       {},
+}
+
+// ValidCategory reports whether category is one of the known canonical category IDs.
+// Requests are not validated against it as the known list is not exhaustive.
+func ValidCategory(category string) bool {
+	_, ok := categories[Category(category)]
+	return ok
+}
+
+//////////////////////////////////////////////////////////////////
+
+type Categories []Category
+type Category string
+
+func (c Categories) strings() []string {
+	res := make([]string, 0, len(c))
+
+	for _, val := range c {
+		res = append(res, string(val))
+	}
+
+	return res
+}
+
+func (c Categories) query() string {
+	return strings.Join(c.strings(), ",")
+}
+
+//////////////////////////////////////////////////////////////////
+
+// Categories returns the lower case POI categories of the feature: the comma-separated geocoding category, e.g.
+// "coffee, tea, cafe", or the Search Box category names and canonical IDs. Empty for other features.
+func (f *Feature) Categories() []string {
+	if f == nil || f.Properties == nil {
+		return nil
+	}
+
+	var categories []string
+	if f.Properties.Category != "" {
+		for _, category := range strings.Split(f.Properties.Category, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				categories = append(categories, strings.ToLower(category))
+			}
+		}
+	}
+	for _, category := range f.Properties.POICategory {
+		categories = append(categories, strings.ToLower(category))
+	}
+	for _, category := range f.Properties.POICategoryIDs {
+		categories = append(categories, strings.ToLower(category))
+	}
+	return categories
+}
+
+// FilterByCategory returns the features with any of the categories, compared case-insensitively, e.g.
+// CategoryRestaurant to keep the restaurants among mixed POIs. It is a client-side filter of the results,
+// the filtered out features are still billed.
+func (f Features) FilterByCategory(categories ...Category) Features {
+	wanted := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		wanted[strings.ToLower(string(category))] = true
+	}
+
+	var filtered Features
+	for _, feature := range f {
+		for _, category := range feature.Categories() {
+			if wanted[category] {
+				filtered = append(filtered, feature)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// FilterByCategory returns the response features with any of the categories, see Features.FilterByCategory
+func (r *ForwardGeocodeResponse) FilterByCategory(categories ...Category) Features {
+	return r.Features.FilterByCategory(categories...)
+}
@@ -0,0 +1,60 @@
+package mapbox
+
+import (
+	"testing"
+)
+
+func TestValidCategory(t *testing.T) {
+	for _, category := range []string{string(CategoryCoffee), "restaurant", string(CategoryEVChargingStation)} {
+		if !ValidCategory(category) {
+			t.Errorf("expected %q to be valid", category)
+		}
+	}
+	for _, category := range []string{"", "Coffee", "coffee shop", "restaurants"} {
+		if ValidCategory(category) {
+			t.Errorf("expected %q to be invalid", category)
+		}
+	}
+}
+
+func TestCategoriesQuery(t *testing.T) {
+	tests := []struct {
+		categories Categories
+		expected   string
+	}{
+		{nil, ""},
+		{Categories{CategoryCoffee}, "coffee"},
+		{Categories{CategoryCoffee, CategoryBakery}, "coffee,bakery"},
+	}
+
+	for _, test := range tests {
+		if actual := test.categories.query(); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestFeaturesFilterByCategory(t *testing.T) {
+	response := &ForwardGeocodeResponse{Features: Features{
+		{ID: "poi.1", Properties: &Properties{Category: "restaurant, Italian Restaurant"}},
+		{ID: "poi.2", Properties: &Properties{Category: "coffee, tea, cafe"}},
+		{ID: "poi.3", Properties: &Properties{POICategory: []string{"Restaurant"}, POICategoryIDs: []string{"restaurant"}}},
+		{ID: "place.1"},
+		nil,
+	}}
+
+	var ids []string
+	for _, feature := range response.FilterByCategory(CategoryRestaurant, "bakery") {
+		ids = append(ids, feature.ID)
+	}
+	if expected := []string{"poi.1", "poi.3"}; !equalStrings(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+
+	if categories := response.Features[1].Categories(); !equalStrings(categories, []string{"coffee", "tea", "cafe"}) {
+		t.Errorf("unexpected categories %v", categories)
+	}
+	if len(response.FilterByCategory()) != 0 {
+		t.Errorf("expected no features without categories")
+	}
+}
@@ -0,0 +1,77 @@
+package mapbox
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// circuitBreaker short-circuits requests after a number of consecutive rate-limit/5xx responses.
+// Once the cooldown has passed a single probe request is let through, a successful probe closes the circuit again.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mutex    sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow returns ErrCircuitOpen when the request must not be sent
+func (cb *circuitBreaker) allow() error {
+	if cb == nil {
+		return nil
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	// closed
+	if cb.failures < cb.threshold {
+		return nil
+	}
+	// open, or half-open with a probe already in flight
+	if cb.now().Sub(cb.openedAt) < cb.cooldown || cb.probing {
+		return ErrCircuitOpen
+	}
+	// half-open, let a probe through
+	cb.probing = true
+	return nil
+}
+
+// done records the outcome of a request let through by allow
+func (cb *circuitBreaker) done(response *http.Response, err error) {
+	if cb == nil {
+		return
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.probing = false
+
+	// transport errors say nothing about the state of the Mapbox API
+	if err != nil {
+		return
+	}
+
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode < 500 {
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		cb.openedAt = cb.now()
+	}
+}
@@ -0,0 +1,66 @@
+package mapbox
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	cb := newCircuitBreaker(2, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable}
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	// trips after two consecutive failures
+	for i := 0; i < 2; i++ {
+		if err := cb.allow(); err != nil {
+			t.Fatalf("expected closed circuit, got %v", err)
+		}
+		cb.done(unavailable, nil)
+	}
+	if err := cb.allow(); err != ErrCircuitOpen {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// half-open after the cooldown, only a single probe is let through
+	now = now.Add(time.Minute)
+	if err := cb.allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	if err := cb.allow(); err != ErrCircuitOpen {
+		t.Fatalf("expected ErrCircuitOpen while probing, got %v", err)
+	}
+
+	// failed probe re-opens the circuit
+	cb.done(unavailable, nil)
+	if err := cb.allow(); err != ErrCircuitOpen {
+		t.Fatalf("expected ErrCircuitOpen after failed probe, got %v", err)
+	}
+
+	// successful probe closes the circuit
+	now = now.Add(time.Minute)
+	if err := cb.allow(); err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	cb.done(ok, nil)
+	if err := cb.allow(); err != nil {
+		t.Fatalf("expected closed circuit, got %v", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	cb := newCircuitBreaker(1, time.Minute)
+
+	cb.done(&http.Response{StatusCode: http.StatusNotFound}, nil)
+	if err := cb.allow(); err != nil {
+		t.Fatalf("expected 404 not to trip the circuit, got %v", err)
+	}
+
+	cb.done(&http.Response{StatusCode: http.StatusTooManyRequests}, nil)
+	if err := cb.allow(); err != ErrCircuitOpen {
+		t.Fatalf("expected 429 to trip the circuit, got %v", err)
+	}
+}
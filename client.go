@@ -3,11 +3,14 @@ package mapbox
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,6 +18,8 @@ import (
 const (
 	ResponseOK = "Ok"
 
+	featureCollection = "FeatureCollection"
+
 	baseUrl = "https://api.mapbox.com"
 	v1      = "v1"
 	v5      = "v5"
@@ -24,9 +29,90 @@ type MapboxConfig struct {
 	Timeout time.Duration
 	APIKey  string
 
+	// Optional TokenProvider used instead of a static APIKey, e.g. when tokens rotate.
+	// The token is fetched lazily and refreshed once per request when Mapbox responds with 401.
+	TokenProvider func(ctx context.Context) (string, error)
+
+	// Optional sending of the token as an "Authorization: Bearer" header instead of the access_token query
+	// parameter, for proxies that expect it and to keep tokens out of logged URLs. Mapbox itself documents
+	// the query parameter, only enable it when the requests go through such a proxy.
+	TokenInHeader bool
+
 	// Optional http.Client can be defined in config if specific options are needed
 	// If not provided will default to the stdlib http.Client
 	Client HTTPClient
+
+	// Optional timeouts of the default transport, for flaky networks: establishing the connection, the TLS
+	// handshake and waiting for the response headers once the request is sent. Unset keeps the http.DefaultTransport
+	// values. They only apply when Client is unset, with a custom Client they are ignored and the Logger warned.
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// Optional middleware wrapping the transport, e.g. for tracing or request mutation. It is applied once in
+	// NewClient to the transport of Client (http.DefaultTransport when unset), which must then be an *http.Client.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
+	// Optional directory to record responses to and replay them from, see Recorder
+	RecorderDir string
+
+	// Optional decoding of the geocoding and Search Box features one by one, so a malformed feature doesn't fail the
+	// whole response. The features that failed are reported by a PartialDecodeError returned with the response.
+	LenientDecoding bool
+
+	// Optional Logger for soft validation warnings, e.g. a response of an unexpected type or a forward geocoding
+	// Proximity outside its BBox. *log.Logger implements it.
+	Logger Logger
+	// Optional StrictValidation turns the soft validation warnings into errors
+	StrictValidation bool
+
+	// Optional cache of the forward geocoding responses, keyed by the request with its NormalizeAddress search
+	// text, so "123 Main St." and "123 main st" share an entry. Cached responses have no ResponseMeta.
+	GeocodeCache Cache
+
+	// Optional deduplication of concurrent identical GET requests, which then share one HTTP call and its
+	// result or error. The call runs with the context of the first caller, its cancellation fails every waiter.
+	Singleflight bool
+
+	// Optional cap on the requests in flight at once, until their response arrives, to bound sockets and memory.
+	// Requests over the cap wait for a slot, or fail with the context error when it is done first.
+	// Complements the rate limiting over time. A request waiting to be retried gives its slot up meanwhile.
+	MaxConcurrency int
+
+	// Optional callback invoked after every request sent to Mapbox, retries included, e.g. for metrics.
+	// Tag requests with WithTag to attribute usage, e.g. per tenant.
+	Observer func(RequestInfo)
+
+	// Optional number of latest requests per endpoint the Client.Stats latency percentiles are computed over,
+	// e.g. 1000. Unset disables the statistics.
+	LatencyWindow int
+
+	// Optional limit (1-10) applied to geocoding requests that don't set one explicitly
+	DefaultLimit int
+
+	// Optional circuit breaker, after CircuitBreakerThreshold consecutive rate-limit/5xx responses
+	// requests fail with ErrCircuitOpen until CircuitBreakerCooldown has passed
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+
+	// Optional number of retries for transient failures (500, 502, 503, 504, or RetryableStatuses when set, and
+	// the network errors of IsRetryable, e.g. a reset connection).
+	// Retries wait a random delay up to RetryBackoff * 2^attempt (default 100ms), capped at RetryMaxBackoff (default 10s),
+	// or the Retry-After of the response when longer. A Retry-After beyond RetryMaxBackoff returns the response as is.
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+	// Optional 4xx/5xx status codes to retry instead of the defaults, e.g. adding 429 behind a proxy that
+	// rate limits on its own. Mapbox 429 responses are otherwise handled by the rate limiting.
+	RetryableStatuses []int
+
+	// Optional unit system of the readable route helpers, e.g. Route.DistanceReadable. Defaults to UnitsMetric.
+	Units Units
+
+	// Optional number of decimals (1-15) geocoding coordinates are rounded to in the query, e.g. 6 ≈ 0.1m.
+	// Rounding shortens URLs and improves cache hit rates. Unset (0) or -1 sends full precision, rounding to
+	// whole degrees isn't supported. Requests can override it with their own CoordinatePrecision.
+	CoordinatePrecision int
 }
 
 // RateLimit represents a set of operations that share a rate limit
@@ -37,12 +123,28 @@ const (
 	GeocodingRateLimit  = "geocoding"
 	MatrixRateLimit     = "matrix"
 	DirectionsRateLimit = "directions"
+	SearchBoxRateLimit  = "searchbox"
+	TokensRateLimit     = "tokens"
 )
 
 type HTTPClient interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Cache stores response bodies by key, e.g. in memory or in Redis, it must be safe for concurrent use.
+// Expiry is up to the implementation.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, body []byte)
+}
+
+// Client is safe for concurrent use by multiple goroutines, share one per application to share its rate limit,
+// circuit breaker and token state. Set Referer before the client is shared, and make the Observer and
+// TokenProvider callbacks safe for concurrent calls.
 type Client struct {
 	httpClient HTTPClient
 	apiKey     string
@@ -50,6 +152,29 @@ type Client struct {
 	Referer        string
 	rateLimits     map[RateLimit]time.Time
 	rateLimitMutex sync.RWMutex
+	defaultLimit   int
+	circuitBreaker *circuitBreaker
+	tokenProvider  func(ctx context.Context) (string, error)
+	tokenMutex     sync.Mutex
+	retry          retryPolicy
+	// decimals geocoding coordinates are rounded to, -1 for full precision
+	coordinatePrecision int
+	units               Units
+	observer            func(RequestInfo)
+	// shares concurrent identical requests, nil when disabled
+	flights *flightGroup
+	// sends the token as a bearer Authorization header
+	tokenInHeader bool
+	logger        Logger
+	strict        bool
+	// slots of the requests in flight, nil when unbounded
+	slots chan struct{}
+	// latency percentiles per endpoint, nil when disabled
+	latency *latencyStats
+	// forward geocoding responses, nil when disabled
+	geocodeCache Cache
+	// decodes the features one by one
+	lenient bool
 }
 
 // NewClient instantiates a new Mapbox client.
@@ -59,24 +184,160 @@ func NewClient(config *MapboxConfig) (*Client, error) {
 		config.Timeout = 30 * time.Second
 	}
 
-	if config.APIKey == "" {
+	if config.APIKey == "" && config.TokenProvider == nil {
 		return nil, fmt.Errorf("missing Mapbox API key")
 	}
 
+	if config.DefaultLimit != 0 && (config.DefaultLimit < 1 || config.DefaultLimit > 10) {
+		return nil, fmt.Errorf("default limit must be between 1 and 10, got %v", config.DefaultLimit)
+	}
+
+	switch config.Units {
+	case "", UnitsMetric, UnitsImperial:
+	default:
+		return nil, fmt.Errorf("unknown units %q", config.Units)
+	}
+
+	if err := validatePrecision(config.CoordinatePrecision); err != nil {
+		return nil, err
+	}
+	coordinatePrecision := config.CoordinatePrecision
+	if coordinatePrecision == 0 {
+		coordinatePrecision = -1
+	}
+
+	var breaker *circuitBreaker
+	if config.CircuitBreakerThreshold < 0 {
+		return nil, fmt.Errorf("circuit breaker threshold must be positive, got %v", config.CircuitBreakerThreshold)
+	}
+	if config.CircuitBreakerThreshold > 0 {
+		if config.CircuitBreakerCooldown <= 0 {
+			return nil, fmt.Errorf("circuit breaker cooldown must be positive, got %v", config.CircuitBreakerCooldown)
+		}
+		breaker = newCircuitBreaker(config.CircuitBreakerThreshold, config.CircuitBreakerCooldown)
+	}
+
+	if config.MaxRetries < 0 || config.RetryBackoff < 0 || config.RetryMaxBackoff < 0 {
+		return nil, fmt.Errorf("retry options must be positive")
+	}
+	retry := retryPolicy{
+		maxRetries: config.MaxRetries,
+		backoff:    config.RetryBackoff,
+		maxBackoff: config.RetryMaxBackoff,
+	}
+	if retry.backoff == 0 {
+		retry.backoff = defaultRetryBackoff
+	}
+	if len(config.RetryableStatuses) != 0 {
+		retry.statuses = make(map[int]bool, len(config.RetryableStatuses))
+		for _, code := range config.RetryableStatuses {
+			if code < 400 || code > 599 {
+				return nil, fmt.Errorf("retryable statuses must be 4xx or 5xx codes, got %v", code)
+			}
+			retry.statuses[code] = true
+		}
+	}
+	if retry.maxBackoff == 0 {
+		retry.maxBackoff = defaultRetryMaxBackoff
+	}
+
+	if config.DialTimeout < 0 || config.TLSHandshakeTimeout < 0 || config.ResponseHeaderTimeout < 0 {
+		return nil, fmt.Errorf("transport timeouts must be positive")
+	}
+	transportTimeouts := config.DialTimeout != 0 || config.TLSHandshakeTimeout != 0 || config.ResponseHeaderTimeout != 0
+
 	var httpClient HTTPClient
 	if config.Client != nil {
 		httpClient = config.Client
+		if transportTimeouts && config.Logger != nil {
+			config.Logger.Printf("mapbox: transport timeouts are ignored with a custom Client")
+		}
 	} else {
-		httpClient = &http.Client{Timeout: config.Timeout}
+		client := &http.Client{Timeout: config.Timeout}
+		if transportTimeouts {
+			client.Transport = timeoutTransport(config)
+		}
+		httpClient = client
+	}
+
+	if config.WrapTransport != nil {
+		base, ok := httpClient.(*http.Client)
+		if !ok {
+			return nil, fmt.Errorf("wrap transport requires an *http.Client, got %T", httpClient)
+		}
+		// copy so the caller's client is left untouched
+		wrapped := *base
+		transport := wrapped.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		wrapped.Transport = config.WrapTransport(transport)
+		httpClient = &wrapped
+	}
+
+	if config.RecorderDir != "" {
+		httpClient = NewRecorder(config.RecorderDir, httpClient)
+	}
+
+	if config.MaxConcurrency < 0 {
+		return nil, fmt.Errorf("max concurrency must be positive, got %v", config.MaxConcurrency)
+	}
+	var slots chan struct{}
+	if config.MaxConcurrency > 0 {
+		slots = make(chan struct{}, config.MaxConcurrency)
+	}
+
+	if config.LatencyWindow < 0 {
+		return nil, fmt.Errorf("latency window must be positive, got %v", config.LatencyWindow)
+	}
+	var latency *latencyStats
+	if config.LatencyWindow > 0 {
+		latency = newLatencyStats(config.LatencyWindow)
+	}
+
+	var flights *flightGroup
+	if config.Singleflight {
+		flights = newFlightGroup()
 	}
 
 	return &Client{
-		httpClient: httpClient,
-		apiKey:     config.APIKey,
-		rateLimits: make(map[RateLimit]time.Time),
+		httpClient:          httpClient,
+		apiKey:              config.APIKey,
+		rateLimits:          make(map[RateLimit]time.Time),
+		defaultLimit:        config.DefaultLimit,
+		circuitBreaker:      breaker,
+		tokenProvider:       config.TokenProvider,
+		coordinatePrecision: coordinatePrecision,
+		units:               config.Units,
+		observer:            config.Observer,
+		flights:             flights,
+		tokenInHeader:       config.TokenInHeader,
+		logger:              config.Logger,
+		strict:              config.StrictValidation,
+		slots:               slots,
+		latency:             latency,
+		geocodeCache:        config.GeocodeCache,
+		lenient:             config.LenientDecoding,
+		retry:               retry,
 	}, nil
 }
 
+// timeoutTransport returns a copy of http.DefaultTransport with the config transport timeouts
+func timeoutTransport(config *MapboxConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.DialTimeout != 0 {
+		dialer := &net.Dialer{Timeout: config.DialTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+	if config.TLSHandshakeTimeout != 0 {
+		transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	}
+	if config.ResponseHeaderTimeout != 0 {
+		transport.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+	}
+	return transport
+}
+
 //////////////////////////////////////////////////////////////////
 
 type ErrorResponse struct {
@@ -106,6 +367,57 @@ func (c *Client) ReverseGeocode(ctx context.Context, req *ReverseGeocodeRequest)
 	return reverseGeocode(ctx, c, req)
 }
 
+// ReverseGeocodeWithMeta is ReverseGeocode also returning the HTTP metadata of the response.
+// The metadata is nil when the request failed before a response was received.
+func (c *Client) ReverseGeocodeWithMeta(ctx context.Context, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, *ResponseMeta, error) {
+	ctx, meta := withResponseMeta(ctx)
+	response, err := c.ReverseGeocode(ctx, req)
+	return response, meta.received(), err
+}
+
+// ReverseGeocodeOne returns the single best feature for coordinate.
+// Optional fields of req are honored, its Coordinates and Limit are overridden. A nil req uses EndpointPlaces.
+// Returns ErrNoResults when Mapbox finds no feature.
+func (c *Client) ReverseGeocodeOne(ctx context.Context, coordinate Coordinate, req *ReverseGeocodeRequest) (*Feature, error) {
+	one := ReverseGeocodeRequest{Endpoint: EndpointPlaces}
+	if req != nil {
+		one = *req
+	}
+	one.Coordinates = Coordinates{coordinate}
+	one.Limit = 1
+
+	response, err := c.ReverseGeocode(ctx, &one)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Features) == 0 {
+		return nil, ErrNoResults
+	}
+
+	return response.Features[0], nil
+}
+
+// Coarsen returns the administrative feature of type level containing coordinate, e.g. TypePlace for its city,
+// to record a location without its exact address. Only level is requested, so no address or POI is returned.
+// level must be TypeCountry, TypeRegion, TypePostcode, TypeDistrict, TypePlace, TypeLocality or TypeNeighborhood.
+// Returns ErrNoResults when the coordinate is in no feature of that level, e.g. at sea.
+func (c *Client) Coarsen(ctx context.Context, coordinate Coordinate, level Type) (*Feature, error) {
+	switch level {
+	case TypeCountry, TypeRegion, TypePostcode, TypeDistrict, TypePlace, TypeLocality, TypeNeighborhood:
+	default:
+		return nil, fmt.Errorf("coarsen level must be an administrative type, got %q", level)
+	}
+
+	feature, err := c.ReverseGeocodeOne(ctx, coordinate, &ReverseGeocodeRequest{Endpoint: EndpointPlaces, Types: Types{level}})
+	if err != nil {
+		return nil, err
+	}
+	if !feature.IsAdministrative() {
+		return nil, fmt.Errorf("expected a %v feature, got %v", level, feature.Kind())
+	}
+	return feature, nil
+}
+
 func (c *Client) ForwardGeocode(ctx context.Context, req *ForwardGeocodeRequest) (*ForwardGeocodeResponse, error) {
 	if err := c.checkRateLimit(GeocodingRateLimit); err != nil {
 		return nil, err
@@ -113,6 +425,41 @@ func (c *Client) ForwardGeocode(ctx context.Context, req *ForwardGeocodeRequest)
 	return forwardGeocode(ctx, c, req)
 }
 
+// ForwardGeocodeNear geocodes req biased towards nearPlace, e.g. "coffee" near "Boston": nearPlace is geocoded
+// first, in the req Endpoint, Country and Language, and its location used as the req Proximity. req is left untouched.
+// Note that both lookups are billed.
+func (c *Client) ForwardGeocodeNear(ctx context.Context, req *ForwardGeocodeRequest, nearPlace string) (*ForwardGeocodeResponse, error) {
+	near, err := c.ForwardGeocode(ctx, &ForwardGeocodeRequest{
+		Endpoint:   req.Endpoint,
+		SearchText: nearPlace,
+		Country:    req.Country,
+		Language:   req.Language,
+		Limit:      1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to geocode near place %q. %w", nearPlace, err)
+	}
+	var proximity Coordinate
+	if len(near.Features) != 0 {
+		proximity = near.Features[0].AsProximity()
+	}
+	if proximity.Lat == 0 {
+		return nil, fmt.Errorf("no location found for near place %q", nearPlace)
+	}
+
+	biased := *req
+	biased.Proximity = proximity
+	return c.ForwardGeocode(ctx, &biased)
+}
+
+// ForwardGeocodeWithMeta is ForwardGeocode also returning the HTTP metadata of the response, of the
+// fallback request when FallbackWithoutBBox was used. The metadata is nil when no response was received.
+func (c *Client) ForwardGeocodeWithMeta(ctx context.Context, req *ForwardGeocodeRequest) (*ForwardGeocodeResponse, *ResponseMeta, error) {
+	ctx, meta := withResponseMeta(ctx)
+	response, err := c.ForwardGeocode(ctx, req)
+	return response, meta.received(), err
+}
+
 func (c *Client) Directions(ctx context.Context, req *DirectionsRequest) (*DirectionsResponse, error) {
 	if err := c.checkRateLimit(DirectionsRateLimit); err != nil {
 		return nil, err
@@ -120,6 +467,133 @@ func (c *Client) Directions(ctx context.Context, req *DirectionsRequest) (*Direc
 	return directions(ctx, c, req)
 }
 
+// RouteBetweenAddresses geocodes from and to, taking the top result of each, and requests the directions between
+// their routable points, see Feature.RoutablePoint. Returns ErrNoResults when an address matches nothing. opts may
+// be nil. Note that the two lookups and the directions are billed.
+func (c *Client) RouteBetweenAddresses(ctx context.Context, from, to string, profile Profile, opts *RouteBetweenAddressesOptions) (*AddressRoute, error) {
+	if opts == nil {
+		opts = &RouteBetweenAddressesOptions{}
+	}
+	if opts.AmbiguityThreshold < 0 {
+		return nil, fmt.Errorf("ambiguity threshold must be positive, got %v", opts.AmbiguityThreshold)
+	}
+
+	geocode := func(address string) (*Feature, Coordinate, error) {
+		req := ForwardGeocodeRequest{Endpoint: EndpointPlaces}
+		if opts.Geocode != nil {
+			req = *opts.Geocode
+		}
+		req.SearchText = address
+		req.Limit = 1
+		if opts.RejectAmbiguous {
+			req.Limit = 2
+		}
+
+		response, err := c.ForwardGeocode(ctx, &req)
+		if err != nil {
+			return nil, Coordinate{}, fmt.Errorf("failed to geocode %q. %w", address, err)
+		}
+		if len(response.Features) == 0 || response.Features[0] == nil {
+			return nil, Coordinate{}, fmt.Errorf("failed to geocode %q. %w", address, ErrNoResults)
+		}
+		threshold := opts.AmbiguityThreshold
+		if threshold == 0 {
+			threshold = defaultAmbiguityThreshold
+		}
+		if opts.RejectAmbiguous && response.IsAmbiguous(threshold) {
+			return nil, Coordinate{}, fmt.Errorf("failed to geocode %q, %q and %q are as relevant. %w",
+				address, response.Features[0].PlaceName, response.Features[1].PlaceName, ErrAmbiguousAddress)
+		}
+
+		feature := response.Features[0]
+		coordinate, ok := feature.RoutablePoint()
+		if !ok {
+			return nil, Coordinate{}, fmt.Errorf("geocoded %q to %v without a location", address, feature.ID)
+		}
+		return feature, coordinate, nil
+	}
+
+	fromFeature, fromCoordinate, err := geocode(from)
+	if err != nil {
+		return nil, err
+	}
+	toFeature, toCoordinate, err := geocode(to)
+	if err != nil {
+		return nil, err
+	}
+
+	req := DirectionsRequest{}
+	if opts.Directions != nil {
+		req = *opts.Directions
+	}
+	req.Profile = profile
+	req.Coordinates = Coordinates{fromCoordinate, toCoordinate}
+	response, err := c.Directions(ctx, &req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route from %q to %q. %w", from, to, err)
+	}
+
+	return &AddressRoute{From: fromFeature, To: toFeature, Directions: response}, nil
+}
+
+// SearchBoxForward runs a one-off Search Box forward search, better suited than geocoding for POI queries
+func (c *Client) SearchBoxForward(ctx context.Context, req *SearchBoxForwardRequest) (*SearchBoxForwardResponse, error) {
+	if err := c.checkRateLimit(SearchBoxRateLimit); err != nil {
+		return nil, err
+	}
+	return searchBoxForward(ctx, c, req)
+}
+
+// DirectionsMatrixURL returns the URL DirectionsMatrix would request, without sending it.
+// The URL includes the access token, log it with RedactURL.
+func (c *Client) DirectionsMatrixURL(ctx context.Context, req *DirectionsMatrixRequest) (*url.URL, error) {
+	relPath, query, err := directionsMatrixQuery(c, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.requestURL(ctx, relPath, query)
+}
+
+// ReverseGeocodeURL returns the URL ReverseGeocode would request, without sending it.
+// The URL includes the access token, log it with RedactURL.
+func (c *Client) ReverseGeocodeURL(ctx context.Context, req *ReverseGeocodeRequest) (*url.URL, error) {
+	relPath, query, err := reverseGeocodeQuery(c, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.requestURL(ctx, relPath, query)
+}
+
+// ForwardGeocodeURL returns the URL ForwardGeocode would request, without sending it.
+// The URL includes the access token, log it with RedactURL.
+func (c *Client) ForwardGeocodeURL(ctx context.Context, req *ForwardGeocodeRequest) (*url.URL, error) {
+	relPath, query, err := forwardGeocodeQuery(c, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.requestURL(ctx, relPath, query)
+}
+
+// DirectionsURL returns the URL Directions would request, without sending it.
+// The URL includes the access token, log it with RedactURL.
+func (c *Client) DirectionsURL(ctx context.Context, req *DirectionsRequest) (*url.URL, error) {
+	relPath, query, err := directionsQuery(c, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.requestURL(ctx, relPath, query)
+}
+
+// SearchBoxForwardURL returns the URL SearchBoxForward would request, without sending it.
+// The URL includes the access token, log it with RedactURL.
+func (c *Client) SearchBoxForwardURL(ctx context.Context, req *SearchBoxForwardRequest) (*url.URL, error) {
+	relPath, query, err := searchBoxForwardQuery(c, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.requestURL(ctx, relPath, query)
+}
+
 //////////////////////////////////////////////////////////////////
 
 func (c *Client) get(ctx context.Context, relPath string, query url.Values) (*http.Response, error) {
@@ -127,25 +601,244 @@ func (c *Client) get(ctx context.Context, relPath string, query url.Values) (*ht
 }
 
 func (c *Client) do(ctx context.Context, httpVerb, relPath string, query url.Values) (*http.Response, error) {
-	// remove empty entries
+	start := time.Now()
+	query = cleanQuery(query)
+
+	send := func() (*http.Response, error) {
+		return c.authorizedSend(ctx, httpVerb, relPath, query)
+	}
+
+	var response *http.Response
+	var err error
+	if c.flights == nil || httpVerb != http.MethodGet {
+		response, err = send()
+	} else {
+		// keyed before the token is added to the query
+		key := httpVerb + " " + relPath + "?" + query.Encode()
+		response, err = c.flights.do(ctx, key, send)
+	}
+
+	if meta := responseMeta(ctx); meta != nil && response != nil {
+		meta.fill(response, time.Since(start))
+	}
+	return response, err
+}
+
+// acquire waits for a MaxConcurrency slot, returning the func releasing it
+func (c *Client) acquire(ctx context.Context) (func(), error) {
+	if c.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return func() { <-c.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// authorizedSend sends the request with the access token, refreshing a rejected token once
+func (c *Client) authorizedSend(ctx context.Context, httpVerb, relPath string, query url.Values) (*http.Response, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.send(ctx, httpVerb, relPath, query, token)
+	if err != nil || response.StatusCode != http.StatusUnauthorized || c.tokenProvider == nil {
+		return response, err
+	}
+
+	// the token may have been rotated, refresh it once and retry
+	response.Body.Close()
+	token, err = c.refreshToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(ctx, httpVerb, relPath, query, token)
+}
+
+// send issues the request, retrying transient failures according to the retry policy. Each attempt holds a
+// MaxConcurrency slot, released while waiting for the next one.
+func (c *Client) send(ctx context.Context, httpVerb, relPath string, query url.Values, token string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		release, err := c.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		response, err := c.roundTrip(ctx, httpVerb, relPath, query, token)
+		release()
+		if attempt >= c.retry.maxRetries {
+			return response, err
+		}
+		delay := c.retry.delay(attempt)
+		if err != nil {
+			// network errors, the context errors of the caller aren't retried
+			if ctx.Err() != nil || !IsRetryable(err) {
+				return response, err
+			}
+		} else if !c.retry.retryable(response.StatusCode) {
+			return response, err
+		} else {
+			// wait as long as the server asks, giving up when that's beyond the longest backoff
+			if wait, ok := retryAfter(response.Header, time.Now()); ok {
+				if wait > c.retry.maxBackoff {
+					return response, err
+				}
+				if wait > delay {
+					delay = wait
+				}
+			}
+			response.Body.Close()
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) roundTrip(ctx context.Context, httpVerb, relPath string, query url.Values, token string) (*http.Response, error) {
+	queryToken := token
+	if c.tokenInHeader {
+		queryToken = ""
+	}
+	req, err := http.NewRequestWithContext(ctx, httpVerb, buildURL(relPath, query, queryToken), nil)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	if c.tokenInHeader && token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.Referer != "" {
+		req.Header.Set("Referer", c.Referer)
+	}
+
+	if err := c.circuitBreaker.allow(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	response, err := c.httpClient.Do(req)
+	c.circuitBreaker.done(response, err)
+	err = redactError(err)
+
+	info := RequestInfo{Method: httpVerb, URL: RedactURL(req.URL), Duration: time.Since(start), Err: err}
+	c.latency.record(relPath, info.Duration)
+	if response != nil {
+		info.StatusCode = response.StatusCode
+	}
+	c.observe(ctx, info)
+
+	return response, err
+}
+
+// redactError removes the access token from the URL of a *url.Error, as returned by http.Client
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			urlErr.URL = RedactURL(u)
+		} else {
+			urlErr.URL = ""
+		}
+	}
+	return err
+}
+
+// RedactURL returns the URL with its access token replaced by ***, safe to log, e.g. for the *URL methods results
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	redacted := *u
+	query := redacted.Query()
+	if query.Get("access_token") == "" {
+		return redacted.String()
+	}
+	query.Set("access_token", "***")
+	redacted.RawQuery = strings.Replace(query.Encode(), "access_token=%2A%2A%2A", "access_token=***", 1)
+	return redacted.String()
+}
+
+// requestURL returns the URL a request would be sent to, with the same token handling as live requests.
+// The URL has no token when it is sent in the Authorization header.
+func (c *Client) requestURL(ctx context.Context, relPath string, query url.Values) (*url.URL, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.tokenInHeader {
+		token = ""
+	}
+	return url.Parse(buildURL(relPath, cleanQuery(query), token))
+}
+
+// cacheKey returns the path and sorted query of a request, empty when building it failed
+func cacheKey(relPath string, query url.Values, err error) string {
+	if err != nil {
+		return ""
+	}
+	return relPath + "?" + cleanQuery(query).Encode()
+}
+
+// cleanQuery removes empty entries
+func cleanQuery(query url.Values) url.Values {
+	if query == nil {
+		return url.Values{}
+	}
+
 	for k := range query {
 		if query.Get(k) == "" {
 			query.Del(k)
 		}
 	}
+	return query
+}
+
+func buildURL(relPath string, query url.Values, token string) string {
+	if token != "" {
+		query.Set("access_token", token)
+	}
 
 	// safe to assume '?' as mapbox requires auth token as query param
-	uri := fmt.Sprintf("%v/%v?%v", baseUrl, relPath, query.Encode())
+	return fmt.Sprintf("%v/%v?%v", baseUrl, relPath, query.Encode())
+}
 
-	req, err := http.NewRequestWithContext(ctx, httpVerb, uri, nil)
-	if err != nil {
-		return nil, err
+// token returns the access token, fetching it from the TokenProvider when none is cached
+func (c *Client) token(ctx context.Context) (string, error) {
+	if c.tokenProvider == nil {
+		return c.apiKey, nil
 	}
-	if c.Referer != "" {
-		req.Header.Set("Referer", c.Referer)
+
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.apiKey == "" {
+		token, err := c.tokenProvider(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get Mapbox API key. %w", err)
+		}
+		c.apiKey = token
+	}
+	return c.apiKey, nil
+}
+
+// refreshToken replaces the rejected token, unless another request already refreshed it
+func (c *Client) refreshToken(ctx context.Context, rejected string) (string, error) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.apiKey != rejected {
+		return c.apiKey, nil
 	}
 
-	return c.httpClient.Do(req)
+	token, err := c.tokenProvider(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh Mapbox API key. %w", err)
+	}
+	c.apiKey = token
+	return c.apiKey, nil
 }
 
 func (c *Client) handleResponse(apiResponse *http.Response, response interface{}, rateLimit RateLimit) error {
@@ -163,12 +856,6 @@ func (c *Client) handleResponse(apiResponse *http.Response, response interface{}
 
 	// check for errors from Mapbox API (non 200 response)
 	if apiResponse.StatusCode >= 400 && apiResponse.StatusCode <= 599 {
-		var errorResponse ErrorResponse
-		err := json.Unmarshal(body, &errorResponse)
-		if err != nil {
-			return NewMapboxError(apiResponse.StatusCode, "")
-		}
-
 		// If rate limited, hold off till the next X-Rate-Limit-Reset
 		if apiResponse.StatusCode == 429 {
 			resetUnix, err := strconv.Atoi(apiResponse.Header.Get("X-Rate-Limit-Reset"))
@@ -178,17 +865,87 @@ func (c *Client) handleResponse(apiResponse *http.Response, response interface{}
 				c.rateLimits[rateLimit] = time.Unix(int64(resetUnix), 0)
 			}
 		}
-		return NewMapboxError(apiResponse.StatusCode, errorResponse.Message)
+
+		var errorResponse ErrorResponse
+		err := json.Unmarshal(body, &errorResponse)
+		if err != nil {
+			return nonJSONError(apiResponse.StatusCode, body)
+		}
+
+		mapboxError := NewMapboxError(apiResponse.StatusCode, errorResponse.Message)
+		mapboxError.Details = errorDetails(body)
+		if apiResponse.StatusCode == http.StatusForbidden {
+			if scopeErr, ok := scopeError(mapboxError); ok {
+				return scopeErr
+			}
+		}
+		return mapboxError
 	}
 
 	// convert to response
 	if err := json.Unmarshal(body, &response); err != nil {
+		// decode the features one by one, unless the rest of the response is malformed too
+		if decoder, ok := response.(partialDecoder); ok && c.lenient {
+			if err := decoder.decodePartial(body); err == nil || isPartialDecode(err) {
+				return err
+			}
+		}
+		// e.g. an HTML page of a proxy or firewall served as success
+		if !strings.Contains(apiResponse.Header.Get("Content-Type"), "json") {
+			return nonJSONError(apiResponse.StatusCode, body)
+		}
 		return fmt.Errorf("failed to read body. %w", err)
 	}
 
 	return nil
 }
 
+// maxErrorBody is the length of a non-JSON body kept in a MapboxError
+const maxErrorBody = 512
+
+// nonJSONError returns the error of a response that isn't JSON, e.g. a gateway error page, keeping the start of its body
+func nonJSONError(statusCode int, body []byte) MapboxError {
+	mapboxError := NewMapboxError(statusCode, fmt.Sprintf("non-JSON error response (status %v)", statusCode))
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	mapboxError.Body = string(body)
+	return mapboxError
+}
+
+// checkResponseType reports a decoded response whose type isn't expected, e.g. an error page parsed as success
+func (c *Client) checkResponseType(actual, expected string) error {
+	if actual == expected {
+		return nil
+	}
+	return c.warn(fmt.Errorf("unexpected response type %q, expected %q", actual, expected))
+}
+
+// warn logs a soft validation error and returns nil, or returns it with StrictValidation
+func (c *Client) warn(err error) error {
+	if err == nil || c.strict {
+		return err
+	}
+	if c.logger != nil {
+		c.logger.Printf("mapbox: %v", err)
+	}
+	return nil
+}
+
+// errorDetails returns the fields of an error body besides the message, nil when there are none
+func errorDetails(body []byte) map[string]interface{} {
+	var details map[string]interface{}
+	if err := json.Unmarshal(body, &details); err != nil {
+		return nil
+	}
+
+	delete(details, "message")
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
+
 func (c *Client) rateLimit(rl RateLimit) time.Time {
 	c.rateLimitMutex.RLock()
 	defer c.rateLimitMutex.RUnlock()
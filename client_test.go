@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -195,3 +198,467 @@ func (rlc *rateLimitingClient) Do(req *http.Request) (*http.Response, error) {
 		Header: headers,
 	}, nil
 }
+
+func TestClientTokenProviderRefreshOn401(t *testing.T) {
+	tokens := []string{"stale", "fresh"}
+	calls := 0
+	c, err := NewClient(&MapboxConfig{
+		TokenProvider: func(ctx context.Context) (string, error) {
+			token := tokens[calls]
+			calls++
+			return token, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var sentTokens []string
+	c.httpClient = &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			token := r.URL.Query().Get("access_token")
+			sentTokens = append(sentTokens, token)
+			if token != "fresh" {
+				return &http.Response{StatusCode: http.StatusUnauthorized, Body: ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil
+		}),
+	}
+
+	req := &ReverseGeocodeRequest{Endpoint: EndpointPlaces, Coordinates: Coordinates{{Lat: 33.1, Lng: -117.3}}}
+	if _, err := c.ReverseGeocode(context.Background(), req); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sentTokens) != 2 || sentTokens[0] != "stale" || sentTokens[1] != "fresh" {
+		t.Errorf("expected stale then fresh token, got %v", sentTokens)
+	}
+
+	// only a single refresh is attempted per request
+	tokens = append(tokens, "revoked")
+	c.apiKey = "revoked"
+	sentTokens = nil
+	calls = 2
+	if _, err := c.ReverseGeocode(context.Background(), req); err == nil {
+		t.Fatalf("expected unauthorized error")
+	}
+	if len(sentTokens) != 2 {
+		t.Errorf("expected a single retry, got %v", sentTokens)
+	}
+}
+
+func TestClientErrorDetails(t *testing.T) {
+	client, requests := mockClient(
+		&http.Response{
+			StatusCode: 422,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"Invalid query","code":"InvalidInput","suggested":"main st"}`)),
+		},
+		&http.Response{
+			StatusCode: 404,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"Not Found"}`)),
+		},
+	)
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+
+	req := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "main"}
+
+	_, err := client.ForwardGeocode(context.Background(), req)
+	var mapboxErr MapboxError
+	if !errors.As(err, &mapboxErr) {
+		t.Fatalf("expected MapboxError, got %v", err)
+	}
+	if mapboxErr.Message != "Invalid query" || mapboxErr.Details["code"] != "InvalidInput" || mapboxErr.Details["suggested"] != "main st" {
+		t.Errorf("unexpected error %+v", mapboxErr)
+	}
+
+	_, err = client.ForwardGeocode(context.Background(), req)
+	if !errors.As(err, &mapboxErr) {
+		t.Fatalf("expected MapboxError, got %v", err)
+	}
+	if mapboxErr.Details != nil {
+		t.Errorf("expected no details, got %v", mapboxErr.Details)
+	}
+}
+
+func TestClientNonJSONErrors(t *testing.T) {
+	page := `<html><head><title>502 Bad Gateway</title></head><body>bad gateway</body></html>`
+	client, requests := mockClient(
+		&http.Response{
+			StatusCode: 502,
+			Header:     http.Header{"Content-Type": {"text/html"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(page)),
+		},
+		&http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": {"text/html"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`<html>Access denied</html>`)),
+		},
+	)
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+
+	req := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "main"}
+
+	_, err := client.ForwardGeocode(context.Background(), req)
+	var mapboxErr MapboxError
+	if !errors.As(err, &mapboxErr) {
+		t.Fatalf("expected MapboxError, got %v", err)
+	}
+	if mapboxErr.StatusCode != 502 || mapboxErr.Message != "non-JSON error response (status 502)" || mapboxErr.Body != page {
+		t.Errorf("unexpected error %+v", mapboxErr)
+	}
+
+	_, err = client.ForwardGeocode(context.Background(), req)
+	if !errors.As(err, &mapboxErr) || mapboxErr.StatusCode != 200 || mapboxErr.Body != `<html>Access denied</html>` {
+		t.Errorf("expected a non-JSON error, got %v", err)
+	}
+}
+
+func TestClientRedactsTokenFromErrors(t *testing.T) {
+	c, _ := NewClient(&MapboxConfig{
+		APIKey: "secret-token",
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		})},
+	})
+
+	_, err := c.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("expected the token to be redacted, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "access_token=***") {
+		t.Errorf("expected the redacted URL, got %v", err)
+	}
+
+	u, _ := url.Parse("https://api.mapbox.com/geocoding/v5/mapbox.places/a.json?limit=1&access_token=secret-token")
+	if expected := "https://api.mapbox.com/geocoding/v5/mapbox.places/a.json?access_token=***&limit=1"; RedactURL(u) != expected {
+		t.Errorf("expected %q, got %q", expected, RedactURL(u))
+	}
+}
+
+func TestNewClientWrapTransport(t *testing.T) {
+	base := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("X-Trace") != "span" {
+			t.Errorf("expected the wrapped transport to run first")
+		}
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"features":[]}`))}, nil
+	})}
+
+	wraps := 0
+	c, err := NewClient(&MapboxConfig{
+		APIKey: "test",
+		Client: base,
+		WrapTransport: func(next http.RoundTripper) http.RoundTripper {
+			wraps++
+			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				r.Header.Set("X-Trace", "span")
+				return next.RoundTrip(r)
+			})
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if wraps != 1 {
+		t.Errorf("expected the transport to be wrapped once, got %v", wraps)
+	}
+	if _, ok := base.Transport.(roundTripperFunc); !ok {
+		t.Errorf("expected the base client to be left untouched")
+	}
+
+	if _, err := NewClient(&MapboxConfig{
+		APIKey:        "test",
+		Client:        httpClientFunc(func(r *http.Request) (*http.Response, error) { return nil, nil }),
+		WrapTransport: func(next http.RoundTripper) http.RoundTripper { return next },
+	}); err == nil {
+		t.Errorf("expected error for a non *http.Client")
+	}
+}
+
+type httpClientFunc func(*http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	var observed int32
+	client, err := NewClient(&MapboxConfig{
+		TokenProvider: func(ctx context.Context) (string, error) { return "token", nil },
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"place.1","center":[-117.3,33.1]}]}`)),
+			}, nil
+		})},
+		CircuitBreakerThreshold: 5,
+		CircuitBreakerCooldown:  time.Second,
+		MaxRetries:              1,
+		Singleflight:            true,
+		Observer:                func(RequestInfo) { atomic.AddInt32(&observed, 1) },
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				response, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{
+					Endpoint:   EndpointPlaces,
+					SearchText: fmt.Sprintf("query %v", j%3),
+					Proximity:  Coordinate{Lat: 33.1, Lng: -117.3},
+				})
+				if err != nil || len(response.Features) != 1 {
+					t.Errorf("goroutine %v: unexpected response %+v, %v", i, response, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&observed) == 0 {
+		t.Errorf("expected observed requests")
+	}
+}
+
+func TestClientTokenInHeader(t *testing.T) {
+	var sent *http.Request
+	client, err := NewClient(&MapboxConfig{
+		APIKey:        "secret-token",
+		TokenInHeader: true,
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			sent = r
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`))}, nil
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}
+	if _, err := client.ForwardGeocode(context.Background(), req); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if auth := sent.Header.Get("Authorization"); auth != "Bearer secret-token" {
+		t.Errorf("expected the bearer token header, got %q", auth)
+	}
+	if strings.Contains(sent.URL.String(), "secret-token") {
+		t.Errorf("expected no token in the URL, got %v", sent.URL)
+	}
+
+	u, err := client.ForwardGeocodeURL(context.Background(), req)
+	if err != nil || u.Query().Get("access_token") != "" {
+		t.Errorf("expected a dry-run URL without token, got %v, %v", u, err)
+	}
+}
+
+type printfLogger []string
+
+func (l *printfLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
+
+func TestClientResponseTypeValidation(t *testing.T) {
+	newClient := func(logger Logger, strict bool) *Client {
+		client, err := NewClient(&MapboxConfig{
+			APIKey:           "test",
+			Logger:           logger,
+			StrictValidation: strict,
+			Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"Feature","features":[]}`))}, nil
+			})},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return client
+	}
+	req := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}
+
+	var logged printfLogger
+	if _, err := newClient(&logged, false).ForwardGeocode(context.Background(), req); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], `unexpected response type "Feature"`) {
+		t.Errorf("expected the type mismatch to be logged, got %v", logged)
+	}
+
+	if _, err := newClient(nil, true).ForwardGeocode(context.Background(), req); err == nil || !strings.Contains(err.Error(), "FeatureCollection") {
+		t.Errorf("expected a strict validation error, got %v", err)
+	}
+}
+
+func TestNewClientTransportTimeouts(t *testing.T) {
+	client, err := NewClient(&MapboxConfig{APIKey: "test", TLSHandshakeTimeout: 2 * time.Second, ResponseHeaderTimeout: 5 * time.Second, DialTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	transport, ok := client.httpClient.(*http.Client).Transport.(*http.Transport)
+	if !ok || transport.TLSHandshakeTimeout != 2*time.Second || transport.ResponseHeaderTimeout != 5*time.Second || transport.DialContext == nil {
+		t.Errorf("unexpected transport %+v", transport)
+	}
+	if http.DefaultTransport.(*http.Transport).ResponseHeaderTimeout != 0 {
+		t.Errorf("expected the default transport to be left untouched")
+	}
+
+	var logged printfLogger
+	custom := &http.Client{}
+	client, err = NewClient(&MapboxConfig{APIKey: "test", Client: custom, Logger: &logged, DialTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.httpClient != custom || custom.Transport != nil || len(logged) != 1 {
+		t.Errorf("expected the custom client to be kept and a warning, got %v", logged)
+	}
+
+	if _, err := NewClient(&MapboxConfig{APIKey: "test", DialTimeout: -time.Second}); err == nil {
+		t.Errorf("expected a negative timeout to be rejected")
+	}
+}
+
+func TestClientMaxConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	client, err := NewClient(&MapboxConfig{
+		APIKey:         "test",
+		MaxConcurrency: 2,
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`))}, nil
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak != 2 {
+		t.Errorf("expected at most 2 requests in flight, got %v", peak)
+	}
+
+	// a full client fails with the context error while waiting
+	client.slots <- struct{}{}
+	client.slots <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.ForwardGeocode(ctx, &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline error, got %v", err)
+	}
+}
+
+func TestClientMaxConcurrencyReleasedWhileRetrying(t *testing.T) {
+	var calls int32
+	client, err := NewClient(&MapboxConfig{
+		APIKey:          "test",
+		MaxConcurrency:  1,
+		MaxRetries:      1,
+		RetryMaxBackoff: time.Second,
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     http.Header{"Retry-After": []string{"1"}},
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"maintenance"}`)),
+				}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`))}, nil
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	retried := make(chan error, 1)
+	go func() {
+		_, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"})
+		retried <- err
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the slot is free while the first request waits for its retry
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := client.ForwardGeocode(ctx, &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "oceanside"}); err != nil {
+		t.Errorf("expected the request to run during the retry wait, got %v", err)
+	}
+	if err := <-retried; err != nil {
+		t.Errorf("expected the retry to succeed, got %v", err)
+	}
+}
+
+func TestRequestCacheKeys(t *testing.T) {
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.733810, Lng: -117.193443}}
+
+	forward := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad", Types: Types{TypePlace}, Country: "us"}
+	expected := "geocoding/v5/mapbox.places/carlsbad.json?autocomplete=false&country=us&fuzzyMatch=false&routing=false&types=place"
+	if key := forward.CacheKey(); key != expected {
+		t.Errorf("expected %v, got %v", expected, key)
+	}
+	// the same request built in another order has the same key
+	same := &ForwardGeocodeRequest{Country: "us", Types: Types{TypePlace}, SearchText: "carlsbad", Endpoint: EndpointPlaces}
+	if same.CacheKey() != forward.CacheKey() {
+		t.Errorf("expected equal keys, got %v and %v", same.CacheKey(), forward.CacheKey())
+	}
+
+	directions := &DirectionsRequest{Profile: ProfileDriving, Coordinates: coordinates, Annotations: Annotations{AnnotationDuration}}
+	if key := directions.CacheKey(); !strings.HasPrefix(key, "directions/v5/mapbox/driving/") || !strings.Contains(key, "overview=full") {
+		t.Errorf("unexpected directions key %v", key)
+	}
+	if directions.Overview != "" {
+		t.Errorf("expected the request to be left untouched, got overview %v", directions.Overview)
+	}
+
+	keys := []string{
+		(&ReverseGeocodeRequest{Endpoint: EndpointPlaces, Coordinates: coordinates[:1]}).CacheKey(),
+		(&DirectionsMatrixRequest{Profile: ProfileWalking, Coordinates: coordinates}).CacheKey(),
+		(&SearchBoxForwardRequest{SearchText: "coffee"}).CacheKey(),
+	}
+	for _, key := range keys {
+		if key == "" || strings.Contains(key, "access_token") {
+			t.Errorf("unexpected key %q", key)
+		}
+	}
+
+	if key := (&ForwardGeocodeRequest{}).CacheKey(); key != "" {
+		t.Errorf("expected no key for an invalid request, got %v", key)
+	}
+}
@@ -2,9 +2,13 @@ package mapbox
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 )
 
+// Coordinate is a WGS84 position. float64 holds 15 significant digits exactly, i.e. 12 decimals for any
+// longitude, so decoded coordinates and their full precision formatting round-trip the API values unchanged.
 type Coordinate struct {
 	Lat float64
 	Lng float64
@@ -17,7 +21,9 @@ func (c Coordinate) WGS84Format() string {
 	var b strings.Builder
 	b.Grow(21) // 10(lat) + 10(lng) + 1(comma)
 
-	fmt.Fprintf(&b, "%v,%v", c.Lng, c.Lat)
+	b.WriteString(formatDegrees(c.Lng))
+	b.WriteByte(',')
+	b.WriteString(formatDegrees(c.Lat))
 
 	return b.String()
 }
@@ -25,12 +31,138 @@ func (c Coordinate) WGS84Format() string {
 // https://docs.mapbox.com/api/#coordinate-format
 func (c Coordinates) WGS84Format() string {
 	var b strings.Builder
-	b.Grow(len(c) * 20) // 10(lat) + 10(lng)
+	b.Grow(len(c) * 22) // 10(lat) + 10(lng) + 1(comma) + 1(semicolon)
 
+	for i, coordinate := range c {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		b.WriteString(coordinate.WGS84Format())
+	}
+
+	return b.String()
+}
+
+// consecutiveDuplicate returns the index of the first coordinate identical to the previous one, -1 when there is none
+func (c Coordinates) consecutiveDuplicate() int {
+	for i := 1; i < len(c); i++ {
+		if c[i] == c[i-1] {
+			return i
+		}
+	}
+	return -1
+}
+
+// format returns the coordinate in WGS84 format rounded to decimals, or with full precision when decimals is negative
+func (c Coordinate) format(decimals int) string {
+	if decimals < 0 {
+		return c.WGS84Format()
+	}
+	return Coordinate{Lat: roundDegrees(c.Lat, decimals), Lng: roundDegrees(c.Lng, decimals)}.WGS84Format()
+}
+
+// format returns the coordinates in WGS84 format rounded to decimals, or with full precision when decimals is negative
+func (c Coordinates) format(decimals int) string {
+	if decimals < 0 {
+		return c.WGS84Format()
+	}
+
+	rounded := make(Coordinates, 0, len(c))
 	for _, coordinate := range c {
-		fmt.Fprintf(&b, "%v;", coordinate.WGS84Format())
+		rounded = append(rounded, Coordinate{Lat: roundDegrees(coordinate.Lat, decimals), Lng: roundDegrees(coordinate.Lng, decimals)})
+	}
+	return rounded.WGS84Format()
+}
+
+// WKT returns the coordinates as a Well-Known Text LineString, e.g. a route Coordinates as
+// "LINESTRING(-117.3 33.1,-117.2 33.2)". At least 2 coordinates are required.
+func (c Coordinates) WKT() (string, error) {
+	positions, err := wktPositions(c.positions())
+	if err != nil {
+		return "", err
 	}
+	return "LINESTRING" + positions, nil
+}
+
+// positions returns the coordinates as [longitude, latitude] positions
+func (c Coordinates) positions() [][]float64 {
+	positions := make([][]float64, 0, len(c))
+	for _, coordinate := range c {
+		positions = append(positions, []float64{coordinate.Lng, coordinate.Lat})
+	}
+	return positions
+}
+
+// DistanceTo returns the great-circle distance to other in meters (haversine formula)
+func (c Coordinate) DistanceTo(other Coordinate) float64 {
+	lat1, lat2 := radians(c.Lat), radians(other.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(other.Lng - c.Lng)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// webMercatorExtent is the half width of the EPSG:3857 Web Mercator projection in meters, where it reaches
+// longitude 180 and latitude 85.0511
+const webMercatorExtent = math.Pi * earthRadius
+
+// CoordinateFromWebMercator converts EPSG:3857 Web Mercator meters, e.g. from tile math, into a WGS84 coordinate.
+// Returns an error when x or y is outside the projection extent.
+func CoordinateFromWebMercator(x, y float64) (Coordinate, error) {
+	if !(math.Abs(x) <= webMercatorExtent && math.Abs(y) <= webMercatorExtent) {
+		return Coordinate{}, fmt.Errorf("web mercator %v,%v is outside the ±%v meters extent", x, y, webMercatorExtent)
+	}
+	return Coordinate{
+		Lat: degrees(2*math.Atan(math.Exp(y/earthRadius)) - math.Pi/2),
+		Lng: x / webMercatorExtent * 180,
+	}, nil
+}
+
+// ParseCoordinate parses a "{longitude},{latitude}" pair
+func ParseCoordinate(s string) (Coordinate, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Coordinate{}, fmt.Errorf("invalid coordinate %q, expected {longitude},{latitude}", s)
+	}
+
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return Coordinate{}, fmt.Errorf("invalid longitude in %q. %w", s, err)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return Coordinate{}, fmt.Errorf("invalid latitude in %q. %w", s, err)
+	}
+
+	return Coordinate{Lat: lat, Lng: lng}, nil
+}
+
+// ParseCoordinates parses a ';' separated list of "{longitude},{latitude}" pairs, the inverse of Coordinates.WGS84Format
+func ParseCoordinates(s string) (Coordinates, error) {
+	if s == "" {
+		return Coordinates{}, nil
+	}
+
+	parts := strings.Split(s, ";")
+	coordinates := make(Coordinates, 0, len(parts))
+	for _, part := range parts {
+		coordinate, err := ParseCoordinate(part)
+		if err != nil {
+			return nil, err
+		}
+		coordinates = append(coordinates, coordinate)
+	}
+
+	return coordinates, nil
+}
+
+// formatDegrees formats a latitude/longitude with full precision and without exponent notation
+func formatDegrees(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
 
-	result := b.String()
-	return result[:len(result)-1] // remove trailing ';'
+func roundDegrees(f float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(f*scale) / scale
 }
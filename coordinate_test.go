@@ -0,0 +1,87 @@
+package mapbox
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCoordinatesWGS84Format(t *testing.T) {
+	tests := []struct {
+		coordinates Coordinates
+		expected    string
+	}{
+		{Coordinates{}, ""},
+		{nil, ""},
+		{Coordinates{{Lat: 33.122508, Lng: -117.306786}}, "-117.306786,33.122508"},
+		{
+			Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.733810, Lng: -117.193443}, {Lat: 0, Lng: 0}},
+			"-117.306786,33.122508;-117.193443,32.73381;0,0",
+		},
+		// full precision, no exponent notation
+		{Coordinates{{Lat: 0.00001, Lng: 179.123456789012}}, "179.123456789012,0.00001"},
+	}
+
+	for _, test := range tests {
+		actual := test.coordinates.WGS84Format()
+		if actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestParseCoordinates(t *testing.T) {
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 0.00001, Lng: 179.123456789012}}
+
+	parsed, err := ParseCoordinates(coordinates.WGS84Format())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(coordinates, parsed) {
+		t.Errorf("expected %v, got %v", coordinates, parsed)
+	}
+
+	if parsed, err := ParseCoordinates(""); err != nil || len(parsed) != 0 {
+		t.Errorf("expected empty coordinates, got %v, %v", parsed, err)
+	}
+
+	for _, invalid := range []string{"1", "1,2,3", "a,2", "1,b", "1,2;"} {
+		if _, err := ParseCoordinates(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestCoordinateDistanceTo(t *testing.T) {
+	carlsbad := Coordinate{Lat: 33.122508, Lng: -117.306786}
+	sanDiego := Coordinate{Lat: 32.733810, Lng: -117.193443}
+
+	// ~44.4km as the crow flies
+	if d := carlsbad.DistanceTo(sanDiego); d < 44000 || d > 45000 {
+		t.Errorf("expected ~44.4km, got %v", d)
+	}
+	if d := carlsbad.DistanceTo(carlsbad); d != 0 {
+		t.Errorf("expected 0, got %v", d)
+	}
+	// one degree of longitude at the equator
+	if d := (Coordinate{}).DistanceTo(Coordinate{Lng: 1}); math.Abs(d-111319.49) > 1 {
+		t.Errorf("expected ~111319m, got %v", d)
+	}
+}
+
+func TestCoordinatePrecisionRoundTrip(t *testing.T) {
+	// survey-grade values, 12 decimals is 15 significant digits for longitudes
+	feature := decodeFeature(t, `{"id":"address.1","center":[-117.306786912345,33.122508123456],
+		"geometry":{"type":"Point","coordinates":[-179.999999999999,-89.999999999999]}}`)
+
+	if center := (Coordinates{{Lat: feature.Center[1], Lng: feature.Center[0]}}).WGS84Format(); center != "-117.306786912345,33.122508123456" {
+		t.Errorf("expected the center to round-trip, got %v", center)
+	}
+	coordinate, _ := feature.Coordinate()
+	if formatted := coordinate.format(-1); formatted != "-179.999999999999,-89.999999999999" {
+		t.Errorf("expected the geometry to round-trip, got %v", formatted)
+	}
+	if rounded := coordinate.format(7); rounded != "-180,-90" {
+		t.Errorf("expected 7 decimals rounding, got %v", rounded)
+	}
+}
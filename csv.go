@@ -0,0 +1,116 @@
+package mapbox
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSV columns supported by WriteGeocodeCSV
+const (
+	CSVColumnID          = "id"
+	CSVColumnName        = "name"
+	CSVColumnFullAddress = "full_address"
+	CSVColumnLng         = "lng"
+	CSVColumnLat         = "lat"
+	CSVColumnAccuracy    = "accuracy"
+	CSVColumnRelevance   = "relevance"
+	CSVColumnCountry     = "country"
+)
+
+// DefaultCSVColumns are the columns written by WriteGeocodeCSV when none are given
+var DefaultCSVColumns = []string{
+	CSVColumnID,
+	CSVColumnName,
+	CSVColumnFullAddress,
+	CSVColumnLng,
+	CSVColumnLat,
+	CSVColumnAccuracy,
+	CSVColumnRelevance,
+	CSVColumnCountry,
+}
+
+// WriteGeocodeCSV writes the features as CSV, a header row followed by one row per feature.
+// Values a feature doesn't have are written as empty cells, nil features are skipped.
+// Unknown columns are rejected before anything is written.
+func WriteGeocodeCSV(w io.Writer, features Features, cols []string) error {
+	if len(cols) == 0 {
+		cols = DefaultCSVColumns
+	}
+	for _, col := range cols {
+		if _, ok := csvColumns[col]; !ok {
+			return fmt.Errorf("unknown csv column %q", col)
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(cols); err != nil {
+		return fmt.Errorf("failed to write csv header. %w", err)
+	}
+
+	row := make([]string, len(cols))
+	for _, feature := range features {
+		if feature == nil {
+			continue
+		}
+		for i, col := range cols {
+			row[i] = csvColumns[col](feature)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write csv row. %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+var csvColumns = map[string]func(f *Feature) string{
+	CSVColumnID: func(f *Feature) string {
+		if f.ID == "" && f.Properties != nil {
+			return f.Properties.MapboxID
+		}
+		return f.ID
+	},
+	CSVColumnName: func(f *Feature) string {
+		if f.Properties != nil && f.Properties.Name != "" {
+			return f.Properties.Name
+		}
+		return f.Text
+	},
+	CSVColumnFullAddress: func(f *Feature) string {
+		if f.Properties != nil && f.Properties.FullAddress != "" {
+			return f.Properties.FullAddress
+		}
+		return f.PlaceName
+	},
+	CSVColumnLng: func(f *Feature) string {
+		if c, ok := f.Coordinate(); ok {
+			return formatDegrees(c.Lng)
+		}
+		return ""
+	},
+	CSVColumnLat: func(f *Feature) string {
+		if c, ok := f.Coordinate(); ok {
+			return formatDegrees(c.Lat)
+		}
+		return ""
+	},
+	CSVColumnAccuracy: func(f *Feature) string {
+		if f.Properties != nil {
+			return f.Properties.Accuracy
+		}
+		return ""
+	},
+	CSVColumnRelevance: func(f *Feature) string {
+		if f.Relevance == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(f.Relevance, 'f', -1, 64)
+	},
+	CSVColumnCountry: func(f *Feature) string {
+		country, _ := f.Country()
+		return country
+	},
+}
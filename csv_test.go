@@ -0,0 +1,34 @@
+package mapbox
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteGeocodeCSV(t *testing.T) {
+	features := Features{
+		decodeFeature(t, addressFeatureJSON),
+		nil,
+		{ID: "poi.1", Text: "Coffee, Tea & More"},
+	}
+
+	var b bytes.Buffer
+	if err := WriteGeocodeCSV(&b, features, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "id,name,full_address,lng,lat,accuracy,relevance,country\n" +
+		`address.4356035406756260,Hidden Valley Road,"6005 Hidden Valley Road, Carlsbad, California 92011, United States",-117.31,33.1226,rooftop,1,United States` + "\n" +
+		`poi.1,"Coffee, Tea & More",,,,,,` + "\n"
+	if b.String() != expected {
+		t.Errorf("expected:\n%s, got:\n%s", expected, b.String())
+	}
+
+	b.Reset()
+	if err := WriteGeocodeCSV(&b, features, []string{CSVColumnID, "phone"}); err == nil {
+		t.Errorf("expected unknown column error")
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", b.String())
+	}
+}
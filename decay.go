@@ -0,0 +1,56 @@
+package mapbox
+
+import (
+	"math"
+)
+
+// DecayFunc maps a travel time in seconds to a weight, usually 1 at 0 seconds decreasing towards 0
+type DecayFunc func(seconds float64) float64
+
+// DecayWeights applies the decay f to every cell of a Matrix durations, e.g. DirectionsMatrixResponse.Durations,
+// turning travel times into accessibility weights. Null (unroutable) and NaN durations weigh 0.
+func DecayWeights(durations [][]*float64, f DecayFunc) [][]float64 {
+	weights := make([][]float64, len(durations))
+	for i, row := range durations {
+		weights[i] = make([]float64, len(row))
+		for j, duration := range row {
+			if duration == nil || math.IsNaN(*duration) {
+				continue
+			}
+			if w := f(*duration); !math.IsNaN(w) {
+				weights[i][j] = w
+			}
+		}
+	}
+	return weights
+}
+
+// LinearDecay returns a weight decreasing linearly from 1 at 0 seconds to 0 at cutoff seconds and beyond
+func LinearDecay(cutoff float64) DecayFunc {
+	return func(seconds float64) float64 {
+		if cutoff <= 0 || seconds >= cutoff {
+			return 0
+		}
+		return 1 - math.Max(0, seconds)/cutoff
+	}
+}
+
+// ExponentialDecay returns a weight halving every halfLife seconds, exp(-ln2 * seconds / halfLife)
+func ExponentialDecay(halfLife float64) DecayFunc {
+	return func(seconds float64) float64 {
+		if halfLife <= 0 {
+			return 0
+		}
+		return math.Exp(-math.Ln2 * math.Max(0, seconds) / halfLife)
+	}
+}
+
+// GaussianDecay returns a weight following a Gaussian of standard deviation sigma seconds, exp(-seconds² / 2sigma²)
+func GaussianDecay(sigma float64) DecayFunc {
+	return func(seconds float64) float64 {
+		if sigma <= 0 {
+			return 0
+		}
+		return math.Exp(-seconds * seconds / (2 * sigma * sigma))
+	}
+}
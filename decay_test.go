@@ -0,0 +1,43 @@
+package mapbox
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecayWeights(t *testing.T) {
+	value := func(f float64) *float64 { return &f }
+	durations := [][]*float64{
+		{value(0), value(600), nil},
+		{value(1200), value(math.NaN()), value(2400)},
+	}
+
+	weights := DecayWeights(durations, LinearDecay(1200))
+	expected := [][]float64{{1, 0.5, 0}, {0, 0, 0}}
+	for i := range expected {
+		for j := range expected[i] {
+			if math.Abs(weights[i][j]-expected[i][j]) > 1e-9 {
+				t.Errorf("linear %v,%v: expected %v, got %v", i, j, expected[i][j], weights[i][j])
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		f        DecayFunc
+		seconds  float64
+		expected float64
+	}{
+		{"exponential half life", ExponentialDecay(600), 600, 0.5},
+		{"exponential two half lives", ExponentialDecay(600), 1200, 0.25},
+		{"exponential origin", ExponentialDecay(600), 0, 1},
+		{"gaussian sigma", GaussianDecay(600), 600, math.Exp(-0.5)},
+		{"gaussian origin", GaussianDecay(600), 0, 1},
+		{"linear invalid cutoff", LinearDecay(0), 10, 0},
+	}
+	for _, test := range tests {
+		if w := test.f(test.seconds); math.Abs(w-test.expected) > 1e-9 {
+			t.Errorf("%v: expected %v, got %v", test.name, test.expected, w)
+		}
+	}
+}
@@ -32,12 +32,115 @@ type DirectionsMatrixResponse struct {
 	Sources      []Waypoint   `json:"sources"`
 }
 
+// ODPair is an origin-destination cell of a DirectionsMatrixResponse, see DirectionsMatrixResponse.Pairs
+type ODPair struct {
+	Source      int        // the row, an index into the response Sources
+	Destination int        // the column, an index into the response Destinations
+	From        Coordinate // the snapped source location, zero when the response has no Sources
+	To          Coordinate // the snapped destination location, zero when the response has no Destinations
+	Duration    float64    // seconds, 0 when not requested or not Routable
+	Distance    float64    // meters, 0 when not requested or not Routable
+	// Routable is false when Mapbox found no route, i.e. a requested annotation is null
+	Routable bool
+}
+
+// Pairs flattens the matrices into one ODPair per source and destination, row by row, e.g. for CSV export or
+// database insertion. Unroutable pairs are kept and flagged, callers can skip them.
+func (r *DirectionsMatrixResponse) Pairs() []ODPair {
+	rows := len(r.Durations)
+	if len(r.Distances) > rows {
+		rows = len(r.Distances)
+	}
+
+	var pairs []ODPair
+	for i := 0; i < rows; i++ {
+		var durations, distances []*float64
+		if i < len(r.Durations) {
+			durations = r.Durations[i]
+		}
+		if i < len(r.Distances) {
+			distances = r.Distances[i]
+		}
+		columns := len(durations)
+		if len(distances) > columns {
+			columns = len(distances)
+		}
+
+		for j := 0; j < columns; j++ {
+			pair := ODPair{Source: i, Destination: j, Routable: true}
+			if i < len(r.Sources) {
+				pair.From = waypointCoordinate(r.Sources[i])
+			}
+			if j < len(r.Destinations) {
+				pair.To = waypointCoordinate(r.Destinations[j])
+			}
+			if durations != nil {
+				pair.Routable = j < len(durations) && durations[j] != nil
+				if pair.Routable {
+					pair.Duration = *durations[j]
+				}
+			}
+			if distances != nil {
+				if j < len(distances) && distances[j] != nil {
+					pair.Distance = *distances[j]
+				} else {
+					pair.Routable = false
+				}
+			}
+			if !pair.Routable {
+				pair.Duration, pair.Distance = 0, 0
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+func waypointCoordinate(w Waypoint) Coordinate {
+	if len(w.Location) < 2 {
+		return Coordinate{}
+	}
+	return Coordinate{Lat: w.Location[1], Lng: w.Location[0]}
+}
+
+const (
+	matrixMaxCoordinates        = 25
+	matrixTrafficMaxCoordinates = 10
+)
+
+func (r *DirectionsMatrixRequest) validate() error {
+	max := matrixMaxCoordinates
+	if r.Profile == ProfileDrivingTraffic {
+		max = matrixTrafficMaxCoordinates
+	}
+	if len(r.Coordinates) > max {
+		return MatrixLimitError{Profile: r.Profile, Max: max, Actual: len(r.Coordinates)}
+	}
+	// one approach per coordinate, an empty approach keeps the default for its coordinate
+	if len(r.Approaches) != 0 && len(r.Approaches) != len(r.Coordinates) {
+		return fmt.Errorf("approaches must match the %v coordinates, got %v", len(r.Coordinates), len(r.Approaches))
+	}
+	if r.FallbackSpeed < 0 {
+		return fmt.Errorf("fallback speed must be positive, got %v", r.FallbackSpeed)
+	}
+	return nil
+}
+
+// CacheKey returns the canonical key of the request, see ForwardGeocodeRequest.CacheKey
+func (r *DirectionsMatrixRequest) CacheKey() string {
+	req := *r
+	return cacheKey(directionsMatrixQuery(&Client{}, &req))
+}
+
 // https://docs.mapbox.com/api/navigation/#matrix
-func directionsMatrix(ctx context.Context, client *Client, req *DirectionsMatrixRequest) (*DirectionsMatrixResponse, error) {
+func directionsMatrixQuery(client *Client, req *DirectionsMatrixRequest) (string, url.Values, error) {
+	if err := req.validate(); err != nil {
+		return "", nil, err
+	}
+
 	relPath := fmt.Sprintf("%v/%v/%v/%v", directionsMatrixPath, v1, req.Profile, req.Coordinates.WGS84Format())
 
 	query := url.Values{}
-	query.Set("access_token", client.apiKey)
 	query.Set("annotations", req.Annotations.query())
 	query.Set("approaches", req.Approaches.query())
 	query.Set("destinations", req.Destinations.query())
@@ -48,6 +151,15 @@ func directionsMatrix(ctx context.Context, client *Client, req *DirectionsMatrix
 		query.Set("depart_at", req.DepartureTime.query())
 	}
 
+	return relPath, query, nil
+}
+
+func directionsMatrix(ctx context.Context, client *Client, req *DirectionsMatrixRequest) (*DirectionsMatrixResponse, error) {
+	relPath, query, err := directionsMatrixQuery(client, req)
+	if err != nil {
+		return nil, err
+	}
+
 	apiResponse, err := client.get(ctx, relPath, query)
 	if err != nil {
 		return nil, err
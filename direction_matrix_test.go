@@ -0,0 +1,95 @@
+package mapbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDirectionsMatrixLimit(t *testing.T) {
+	client, _ := NewClient(&MapboxConfig{APIKey: "test"})
+
+	coordinates := make(Coordinates, 11)
+	for i := range coordinates {
+		coordinates[i] = Coordinate{Lat: 33, Lng: -117 + float64(i)/100}
+	}
+
+	if _, err := client.DirectionsMatrixURL(context.Background(), &DirectionsMatrixRequest{Profile: ProfileDriving, Coordinates: coordinates}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	_, err := client.DirectionsMatrix(context.Background(), &DirectionsMatrixRequest{Profile: ProfileDrivingTraffic, Coordinates: coordinates})
+	var limitErr MatrixLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected MatrixLimitError, got %v", err)
+	}
+	if limitErr.Max != 10 || limitErr.Actual != 11 || limitErr.Profile != ProfileDrivingTraffic {
+		t.Errorf("unexpected limit error %+v", limitErr)
+	}
+}
+
+func TestDirectionsMatrixFallbackSpeed(t *testing.T) {
+	client, _ := NewClient(&MapboxConfig{APIKey: "test"})
+	coordinates := Coordinates{{Lat: 33.1, Lng: -117.3}, {Lat: 32.7, Lng: -117.2}}
+
+	u, err := client.DirectionsMatrixURL(context.Background(), &DirectionsMatrixRequest{Profile: ProfileDriving, Coordinates: coordinates, FallbackSpeed: 42.5})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if speed := u.Query().Get("fallback_speed"); speed != "42.5" {
+		t.Errorf("expected fallback_speed 42.5, got %q", speed)
+	}
+
+	if _, err := client.DirectionsMatrixURL(context.Background(), &DirectionsMatrixRequest{Profile: ProfileDriving, Coordinates: coordinates, FallbackSpeed: -1}); err == nil {
+		t.Errorf("expected a negative fallback speed to be rejected")
+	}
+}
+
+func TestDirectionsMatrixApproaches(t *testing.T) {
+	client, _ := NewClient(&MapboxConfig{APIKey: "test"})
+	coordinates := Coordinates{{Lat: 33.1, Lng: -117.3}, {Lat: 32.7, Lng: -117.2}}
+
+	u, err := client.DirectionsMatrixURL(context.Background(), &DirectionsMatrixRequest{Profile: ProfileDriving, Coordinates: coordinates, Approaches: Approaches{ApproachCurb, ""}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if approaches := u.Query().Get("approaches"); approaches != "curb;" {
+		t.Errorf("expected approaches curb;, got %q", approaches)
+	}
+
+	if _, err := client.DirectionsMatrixURL(context.Background(), &DirectionsMatrixRequest{Profile: ProfileDriving, Coordinates: coordinates, Approaches: Approaches{ApproachCurb}}); err == nil {
+		t.Errorf("expected an approaches length mismatch to be rejected")
+	}
+}
+
+func TestDirectionsMatrixPairs(t *testing.T) {
+	var response DirectionsMatrixResponse
+	body := `{"code":"Ok",
+		"durations":[[0,600.5],[610,null]],
+		"distances":[[0,5000],[5100,null]],
+		"sources":[{"location":[-117.3,33.1]},{"location":[-117.2,32.7]}],
+		"destinations":[{"location":[-117.3,33.1]},{"location":[-117.2,32.7]}]}`
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	pairs := response.Pairs()
+	if len(pairs) != 4 {
+		t.Fatalf("expected 4 pairs, got %v", len(pairs))
+	}
+	expected := ODPair{Source: 0, Destination: 1, From: Coordinate{Lat: 33.1, Lng: -117.3}, To: Coordinate{Lat: 32.7, Lng: -117.2}, Duration: 600.5, Distance: 5000, Routable: true}
+	if pairs[1] != expected {
+		t.Errorf("expected:\n%+v, got:\n%+v", expected, pairs[1])
+	}
+	if unroutable := pairs[3]; unroutable.Routable || unroutable.Source != 1 || unroutable.Destination != 1 || unroutable.Duration != 0 {
+		t.Errorf("expected an unroutable pair, got %+v", unroutable)
+	}
+
+	// durations only, without waypoints, e.g. a LargeMatrix
+	duration := 42.0
+	pairs = (&DirectionsMatrixResponse{Durations: [][]*float64{{&duration}}}).Pairs()
+	if len(pairs) != 1 || !pairs[0].Routable || pairs[0].Duration != 42 || pairs[0].From != (Coordinate{}) {
+		t.Errorf("unexpected pairs %+v", pairs)
+	}
+}
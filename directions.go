@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -36,6 +37,10 @@ type DirectionsRequest struct {
 	WaypointsPerRoute   *bool
 	WaypointNames       WaypointNames
 	WaypointTargets     WaypointTargets
+	// CollapseDuplicates drops a coordinate identical to the previous one, with its approach, waypoint target and
+	// waypoint, instead of failing validation since Mapbox rejects consecutive duplicates. The response legs follow
+	// the collapsed coordinates.
+	CollapseDuplicates bool
 
 	// Optional parameters for the mapbox/walking profile
 	WalkingSpeed float32
@@ -45,23 +50,233 @@ type DirectionsRequest struct {
 	AlleyBias float32
 	ArriveBy  ArriveBy
 	DepartAt  DepartAt
-	MaxHeight int
-	MaxWidth  int
-	MaxWeight int
+	MaxHeight float64 // vehicle height in meters, from 0 to 10
+	MaxWidth  float64 // vehicle width in meters, from 0 to 10
+	MaxWeight float64 // vehicle weight in metric tons, from 0 to 100
 
-	// Optional parameters for the mapbox/driving-traffic profile
+	// Optional parameters for the mapbox/driving-traffic profile, whether the coordinates may snap to roads closed
+	// by live traffic incidents or by static closures, unset keeps the Mapbox default. The closed sections of the
+	// route are reported in RouteLeg.Closures.
 	SnappingIncludeClosures       *bool
 	SnappingIncludeStaticClosures *bool
 }
 
+func (r *DirectionsRequest) validate() error {
+	if len(r.Coordinates) < 2 {
+		return fmt.Errorf("directions require at least 2 coordinates, got %v", len(r.Coordinates))
+	}
+	if i := r.Coordinates.consecutiveDuplicate(); i >= 0 && !r.CollapseDuplicates {
+		return fmt.Errorf("coordinates %v and %v are the same %v, remove the duplicate or set CollapseDuplicates", i-1, i, r.Coordinates[i].WGS84Format())
+	}
+
+	// steps carry the instructions, they can't be requested without them
+	steps := r.Steps != nil && *r.Steps
+	if !steps && ((r.VoiceInstructions != nil && *r.VoiceInstructions) || (r.BannerInstructions != nil && *r.BannerInstructions)) {
+		return fmt.Errorf("voice and banner instructions require steps")
+	}
+	if !steps && r.RoundaboutExits != nil && *r.RoundaboutExits {
+		return fmt.Errorf("roundabout exits require steps")
+	}
+
+	if r.VoiceUnits != "" && r.VoiceUnits != VoiceUnitsImperial && r.VoiceUnits != VoiceUnitsMetric {
+		return fmt.Errorf("invalid voice units %q, expected %v or %v", r.VoiceUnits, VoiceUnitsImperial, VoiceUnitsMetric)
+	}
+	if strings.Contains(r.Language, ",") {
+		return fmt.Errorf("directions support a single language, got %q", r.Language)
+	}
+	if err := validateLanguage(r.Language); err != nil {
+		return err
+	}
+
+	if err := r.validateAnnotations(); err != nil {
+		return err
+	}
+	if err := r.validateDimensions(); err != nil {
+		return err
+	}
+	if (r.SnappingIncludeClosures != nil || r.SnappingIncludeStaticClosures != nil) && r.Profile != ProfileDrivingTraffic {
+		return fmt.Errorf("snapping to closures requires the %v profile, got %v", ProfileDrivingTraffic, r.Profile)
+	}
+
+	// waypoints are indices into the coordinates, any other coordinate is a silent via-point
+	if len(r.Waypoints) != 0 {
+		previous := -1
+		for _, waypoint := range r.Waypoints {
+			index, err := strconv.Atoi(string(waypoint))
+			if err != nil {
+				return fmt.Errorf("invalid waypoint index %q", waypoint)
+			}
+			if index <= previous || index >= len(r.Coordinates) {
+				return fmt.Errorf("waypoint indices must be increasing and reference the %v coordinates, got %v", len(r.Coordinates), r.Waypoints.strings())
+			}
+			previous = index
+		}
+		if first, _ := strconv.Atoi(string(r.Waypoints[0])); first != 0 || previous != len(r.Coordinates)-1 {
+			return fmt.Errorf("waypoints must include the first and last coordinate, got %v", r.Waypoints.strings())
+		}
+	}
+
+	// waypoint names label the waypoints, the first and last coordinate when none are set
+	if len(r.WaypointNames) != 0 {
+		waypoints := len(r.Coordinates)
+		if len(r.Waypoints) != 0 {
+			waypoints = len(r.Waypoints)
+		}
+		if len(r.WaypointNames) != waypoints {
+			return fmt.Errorf("waypoint names must match the %v waypoints, got %v", waypoints, len(r.WaypointNames))
+		}
+	}
+
+	return nil
+}
+
+// CacheKey returns the canonical key of the request, see ForwardGeocodeRequest.CacheKey
+func (r *DirectionsRequest) CacheKey() string {
+	req := *r
+	return cacheKey(directionsQuery(&Client{}, &req))
+}
+
+// annotationProfiles are the only profiles supporting an annotation, the annotations left out are supported by all
+var annotationProfiles = map[Annotation][]Profile{
+	AnnotationCongestion:        {ProfileDrivingTraffic},
+	AnnotationCongestionNumeric: {ProfileDrivingTraffic},
+	AnnotationMaxspeed:          {ProfileDriving, ProfileDrivingTraffic},
+}
+
+// validateAnnotations checks the annotations are compatible with the profile, see annotationProfiles.
+// Annotations also require overview=full, which directionsQuery sets whatever the requested Overview.
+func (r *DirectionsRequest) validateAnnotations() error {
+	for _, annotation := range r.Annotations {
+		profiles, ok := annotationProfiles[annotation]
+		if !ok {
+			continue
+		}
+		supported := false
+		names := make([]string, len(profiles))
+		for i, profile := range profiles {
+			supported = supported || profile == r.Profile
+			names[i] = string(profile)
+		}
+		if !supported {
+			return fmt.Errorf("%v annotation requires the %v profile, got %v", annotation, strings.Join(names, " or "), r.Profile)
+		}
+	}
+	return nil
+}
+
+// validateDimensions checks the vehicle dimensions are in range and only set for the driving profiles,
+// the walking and cycling profiles reject them
+func (r *DirectionsRequest) validateDimensions() error {
+	dimensions := []struct {
+		name  string
+		value float64
+		max   float64
+	}{
+		{"max_height", r.MaxHeight, 10},
+		{"max_width", r.MaxWidth, 10},
+		{"max_weight", r.MaxWeight, 100},
+	}
+	for _, dimension := range dimensions {
+		if dimension.value == 0 {
+			continue
+		}
+		if r.Profile != ProfileDriving && r.Profile != ProfileDrivingTraffic {
+			return fmt.Errorf("%v requires the %v or %v profile, got %v", dimension.name, ProfileDriving, ProfileDrivingTraffic, r.Profile)
+		}
+		if !(dimension.value > 0 && dimension.value <= dimension.max) {
+			return fmt.Errorf("%v must be positive and at most %v, got %v", dimension.name, dimension.max, dimension.value)
+		}
+	}
+	return nil
+}
+
+// withoutDuplicates returns a copy of the request without the coordinates identical to their previous one,
+// dropping their approaches and waypoint targets and remapping the waypoints. Returns the request itself
+// when it has no duplicates.
+func (r *DirectionsRequest) withoutDuplicates() *DirectionsRequest {
+	if r.Coordinates.consecutiveDuplicate() < 0 {
+		return r
+	}
+
+	collapsed := *r
+	collapsed.Coordinates = nil
+	perCoordinate := func(n int) bool { return n == len(r.Coordinates) }
+	if perCoordinate(len(r.Approaches)) {
+		collapsed.Approaches = nil
+	}
+	if perCoordinate(len(r.WaypointTargets)) {
+		collapsed.WaypointTargets = nil
+	}
+
+	// the index of every coordinate in the collapsed coordinates
+	indices := make([]int, len(r.Coordinates))
+	for i, coordinate := range r.Coordinates {
+		if i > 0 && coordinate == r.Coordinates[i-1] {
+			indices[i] = len(collapsed.Coordinates) - 1
+			continue
+		}
+		indices[i] = len(collapsed.Coordinates)
+		collapsed.Coordinates = append(collapsed.Coordinates, coordinate)
+		if perCoordinate(len(r.Approaches)) {
+			collapsed.Approaches = append(collapsed.Approaches, r.Approaches[i])
+		}
+		if perCoordinate(len(r.WaypointTargets)) {
+			collapsed.WaypointTargets = append(collapsed.WaypointTargets, r.WaypointTargets[i])
+		}
+	}
+
+	// waypoints and their names, the coordinates when no waypoints are set
+	waypoints := make([]int, 0, len(r.Coordinates))
+	if len(r.Waypoints) == 0 {
+		for i := range r.Coordinates {
+			waypoints = append(waypoints, i)
+		}
+	}
+	for _, waypoint := range r.Waypoints {
+		index, err := strconv.Atoi(string(waypoint))
+		if err != nil || index < 0 || index >= len(r.Coordinates) {
+			// left for validate to report
+			return &collapsed
+		}
+		waypoints = append(waypoints, index)
+	}
+	names := len(r.WaypointNames) == len(waypoints)
+	if len(r.Waypoints) != 0 {
+		collapsed.Waypoints = nil
+	}
+	if names {
+		collapsed.WaypointNames = nil
+	}
+	previous := -1
+	for i, waypoint := range waypoints {
+		if indices[waypoint] == previous {
+			continue
+		}
+		previous = indices[waypoint]
+		if len(r.Waypoints) != 0 {
+			collapsed.Waypoints = append(collapsed.Waypoints, DirectionWaypoint(strconv.Itoa(previous)))
+		}
+		if names {
+			collapsed.WaypointNames = append(collapsed.WaypointNames, r.WaypointNames[i])
+		}
+	}
+
+	return &collapsed
+}
+
 // https://docs.mapbox.com/api/navigation/directions/#required-parameters
-func directions(ctx context.Context, client *Client, req *DirectionsRequest) (*DirectionsResponse, error) {
+func directionsQuery(client *Client, req *DirectionsRequest) (string, url.Values, error) {
+	if req.CollapseDuplicates {
+		req = req.withoutDuplicates()
+	}
+	if err := req.validate(); err != nil {
+		return "", nil, err
+	}
+
 	relPath := fmt.Sprintf("%v/%v/%v/%v", directionsPath, v5, req.Profile, req.Coordinates.WGS84Format())
 
 	query := url.Values{}
 
-	query.Set("access_token", client.apiKey)
-
 	if req.Alternatives != nil {
 		query.Set("alternatives", strconv.FormatBool(*req.Alternatives))
 	}
@@ -84,9 +299,12 @@ func directions(ctx context.Context, client *Client, req *DirectionsRequest) (*D
 		query.Set("exclude", req.Excludes.query())
 	}
 
-	if req.Geometries != "" {
-		query.Set("geometries", string(req.Geometries))
+	// default to polyline6, the most compact format at full precision
+	geometries := req.Geometries
+	if geometries == "" {
+		geometries = GeometriesPolyline6
 	}
+	query.Set("geometries", string(geometries))
 
 	if len(req.Includes) != 0 {
 		query.Set("include", req.Includes.query())
@@ -159,15 +377,15 @@ func directions(ctx context.Context, client *Client, req *DirectionsRequest) (*D
 	}
 
 	if req.MaxHeight != 0 {
-		query.Set("max_height", strconv.Itoa(req.MaxHeight))
+		query.Set("max_height", strconv.FormatFloat(req.MaxHeight, 'f', -1, 64))
 	}
 
 	if req.MaxWidth != 0 {
-		query.Set("max_width", strconv.Itoa(req.MaxWidth))
+		query.Set("max_width", strconv.FormatFloat(req.MaxWidth, 'f', -1, 64))
 	}
 
 	if req.MaxWeight != 0 {
-		query.Set("max_weight", strconv.Itoa(req.MaxWeight))
+		query.Set("max_weight", strconv.FormatFloat(req.MaxWeight, 'f', -1, 64))
 	}
 
 	if req.SnappingIncludeClosures != nil {
@@ -178,6 +396,15 @@ func directions(ctx context.Context, client *Client, req *DirectionsRequest) (*D
 		query.Set("snapping_include_static_closures", strconv.FormatBool(*req.SnappingIncludeStaticClosures))
 	}
 
+	return relPath, query, nil
+}
+
+func directions(ctx context.Context, client *Client, req *DirectionsRequest) (*DirectionsResponse, error) {
+	relPath, query, err := directionsQuery(client, req)
+	if err != nil {
+		return nil, err
+	}
+
 	apiResponse, err := client.get(ctx, relPath, query)
 	if err != nil {
 		return nil, err
@@ -188,5 +415,51 @@ func directions(ctx context.Context, client *Client, req *DirectionsRequest) (*D
 		return nil, err
 	}
 
+	precision := Polyline6Precision
+	if req.Geometries == GeometriesPolyline {
+		precision = PolylinePrecision
+	}
+	for i := range response.Routes {
+		route := &response.Routes[i]
+		route.units = client.units
+		if err := route.decode(precision); err != nil {
+			return nil, fmt.Errorf("failed to decode route geometry. %w", err)
+		}
+		for j := range route.Legs {
+			for k := range route.Legs[j].Steps {
+				if err := route.Legs[j].Steps[k].decode(precision); err != nil {
+					return nil, fmt.Errorf("failed to decode step geometry. %w", err)
+				}
+			}
+		}
+	}
+
 	return &response, nil
 }
+
+//////////////////////////////////////////////////////////////////
+
+// RouteBetweenAddressesOptions are the optional settings of RouteBetweenAddresses
+type RouteBetweenAddressesOptions struct {
+	// Geocode is the template of both address lookups, e.g. for the Country or Proximity, its SearchText and Limit
+	// are replaced. Defaults to the EndpointPlaces endpoint.
+	Geocode *ForwardGeocodeRequest
+
+	// Directions is the template of the route request, e.g. for the Alternatives or Annotations, its Profile and
+	// Coordinates are replaced.
+	Directions *DirectionsRequest
+
+	// RejectAmbiguous fails with ErrAmbiguousAddress when the top two results of an address have a relevance
+	// within AmbiguityThreshold (default 0.05), see Features.IsAmbiguous, instead of taking the top result
+	RejectAmbiguous    bool
+	AmbiguityThreshold float64
+}
+
+// AddressRoute is the result of RouteBetweenAddresses, the geocoded endpoints and the route between them
+type AddressRoute struct {
+	From       *Feature
+	To         *Feature
+	Directions *DirectionsResponse
+}
+
+const defaultAmbiguityThreshold = 0.05
@@ -1,9 +1,48 @@
 package mapbox
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
 type DirectionsResponse struct {
-	Code   string  `json:"code"`
-	UUID   string  `json:"uuid,omitempty"`
-	Routes []Route `json:"routes"`
+	Code      string     `json:"code"`
+	UUID      string     `json:"uuid,omitempty"`
+	Routes    []Route    `json:"routes"`
+	Waypoints []Waypoint `json:"waypoints,omitempty"` // named after the WaypointNames when set, per route with WaypointsPerRoute
+}
+
+// Fastest returns the route with the shortest duration, the first one on ties. Returns false without routes.
+func (r *DirectionsResponse) Fastest() (*Route, bool) {
+	ranked := r.RankByDuration()
+	if len(ranked) == 0 {
+		return nil, false
+	}
+	return ranked[0], true
+}
+
+// Shortest returns the route with the shortest distance, the first one on ties. Returns false without routes.
+func (r *DirectionsResponse) Shortest() (*Route, bool) {
+	var shortest *Route
+	for i := range r.Routes {
+		if shortest == nil || r.Routes[i].Distance < shortest.Distance {
+			shortest = &r.Routes[i]
+		}
+	}
+	return shortest, shortest != nil
+}
+
+// RankByDuration returns the routes from the fastest to the slowest, keeping the Mapbox order on ties.
+// Routes is left in the Mapbox order, its first route being the recommended one.
+func (r *DirectionsResponse) RankByDuration() []*Route {
+	ranked := make([]*Route, 0, len(r.Routes))
+	for i := range r.Routes {
+		ranked = append(ranked, &r.Routes[i])
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Duration < ranked[j].Duration })
+	return ranked
 }
 
 type Route struct {
@@ -13,36 +52,201 @@ type Route struct {
 	Weight          float64    `json:"weight"`
 	DurationTypical float64    `json:"duration_typical,omitempty"`
 	WeightTypical   float64    `json:"weight_typical,omitempty"`
-	Geometry        string     `json:"geometry,omitempty"`
+	Geometry        string     `json:"geometry"` // The encoded polyline, empty for GeoJSON geometries.
 	Legs            []RouteLeg `json:"legs"`
 	VoiceLocale     string     `json:"voiceLocale,omitempty"`
 	Waypoints       []Waypoint `json:"waypoints,omitempty"`
+
+	// Coordinates of the route geometry, decoded regardless of the requested Geometries
+	Coordinates Coordinates `json:"-"`
+
+	// units of the readable helpers, from the client Units
+	units Units
+}
+
+// ETASpread returns the typical and current travel time of the route, e.g. "usually 20 min, currently 28 min".
+// typical is only known for the mapbox/driving-traffic profile and is 0 for other profiles.
+func (r *Route) ETASpread() (typical, current time.Duration) {
+	return seconds(r.DurationTypical), seconds(r.Duration)
+}
+
+// HasClosures reports whether the route goes through a closed road, see RouteLeg.Closures
+func (r *Route) HasClosures() bool {
+	for _, leg := range r.Legs {
+		if len(leg.Closures) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// UnmarshalJSON decodes the route, reading a GeoJSON geometry into Coordinates
+func (r *Route) UnmarshalJSON(data []byte) error {
+	type route Route
+	decoded := struct {
+		*route
+		Geometry lineGeometry `json:"geometry"`
+	}{route: (*route)(r)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	r.Geometry, r.Coordinates = decoded.Geometry.polyline, decoded.Geometry.coordinates
+	return nil
+}
+
+// MarshalJSON encodes the geometry as the polyline, or as a GeoJSON LineString when there is none
+func (r Route) MarshalJSON() ([]byte, error) {
+	type route Route
+	return json.Marshal(struct {
+		route
+		Geometry lineGeometry `json:"geometry"`
+	}{route(r), lineGeometry{r.Geometry, r.Coordinates}})
+}
+
+// decode fills Coordinates from the encoded polyline
+func (r *Route) decode(precision int) error {
+	coordinates, err := decodeLine(r.Geometry, r.Coordinates, precision)
+	if err != nil {
+		return err
+	}
+	r.Coordinates = coordinates
+	return nil
+}
+
+// lineGeometry is a geometry encoded as a polyline or GeoJSON LineString depending on the requested Geometries
+type lineGeometry struct {
+	polyline    string
+	coordinates Coordinates
+}
+
+func (g *lineGeometry) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &g.polyline)
+	}
+	if string(data) == "null" {
+		return nil
+	}
+
+	var lineString struct {
+		Coordinates [][]float64 `json:"coordinates"`
+	}
+	if err := json.Unmarshal(data, &lineString); err != nil {
+		return err
+	}
+
+	g.coordinates = make(Coordinates, 0, len(lineString.Coordinates))
+	for _, position := range lineString.Coordinates {
+		if len(position) < 2 {
+			return fmt.Errorf("invalid position %v", position)
+		}
+		g.coordinates = append(g.coordinates, Coordinate{Lat: position[1], Lng: position[0]})
+	}
+	return nil
+}
+
+func (g lineGeometry) MarshalJSON() ([]byte, error) {
+	if g.polyline != "" || g.coordinates == nil {
+		return json.Marshal(g.polyline)
+	}
+
+	positions := make([][]float64, 0, len(g.coordinates))
+	for _, c := range g.coordinates {
+		positions = append(positions, []float64{c.Lng, c.Lat})
+	}
+	return json.Marshal(struct {
+		Type        string      `json:"type"`
+		Coordinates [][]float64 `json:"coordinates"`
+	}{"LineString", positions})
+}
+
+// decodeLine returns the coordinates of the encoded polyline, or coordinates when there is no polyline (GeoJSON)
+func decodeLine(polyline string, coordinates Coordinates, precision int) (Coordinates, error) {
+	if polyline == "" {
+		return coordinates, nil
+	}
+	return DecodePolyline(polyline, precision)
 }
 
 // RouteLeg represents a leg of the route between two waypoints.
 type RouteLeg struct {
-	Distance           float64              `json:"distance"`           // The distance traveled by the leg, in meters.
-	Duration           float64              `json:"duration"`           // The estimated travel time, in seconds.
-	Summary            string               `json:"summary"`            // A summary of the leg, containing the names of the significant roads.
-	Weight             float64              `json:"weight"`             // The weight of the leg. The weight value is similar to the duration but includes additional factors like traffic.
-	Steps              []Step               `json:"steps"`              // An array of RouteStep objects, each representing a step in the leg.
-	Annotation         DirectionsAnnotation `json:"annotation"`         // Additional details about the leg.
-	Admins             []Admin              `json:"admins"`             // Array of administrative region objects traversed by the leg.
-	VoiceInstructions  []VoiceInstruction   `json:"voiceInstructions"`  // An array of VoiceInstruction objects.
-	BannerInstructions []BannerInstruction  `json:"bannerInstructions"` // An array of BannerInstruction objects.
+	Distance           float64              `json:"distance"`                   // The distance traveled by the leg, in meters.
+	Duration           float64              `json:"duration"`                   // The estimated travel time, in seconds.
+	Summary            string               `json:"summary"`                    // A summary of the leg, containing the names of the significant roads.
+	Weight             float64              `json:"weight"`                     // The weight of the leg. The weight value is similar to the duration but includes additional factors like traffic.
+	DurationTypical    float64              `json:"duration_typical,omitempty"` // The typical travel time, in seconds. Only set for the mapbox/driving-traffic profile.
+	WeightTypical      float64              `json:"weight_typical,omitempty"`   // The typical weight of the leg. Only set for the mapbox/driving-traffic profile.
+	Steps              []Step               `json:"steps"`                      // An array of RouteStep objects, each representing a step in the leg.
+	Annotation         DirectionsAnnotation `json:"annotation"`                 // Additional details about the leg.
+	Admins             []Admin              `json:"admins"`                     // Array of administrative region objects traversed by the leg.
+	VoiceInstructions  []VoiceInstruction   `json:"voiceInstructions"`          // An array of VoiceInstruction objects.
+	BannerInstructions []BannerInstruction  `json:"bannerInstructions"`         // An array of BannerInstruction objects.
 	ViaWaypoints       []ViaWaypoint        `json:"via_waypoints"`
+	Closures           []Closure            `json:"closures,omitempty"` // The closed sections of the leg. Only set for the mapbox/driving-traffic profile.
+}
+
+// Closure is a closed section of a route leg, between two indices into the leg geometry.
+type Closure struct {
+	GeometryIndexStart int `json:"geometry_index_start"` // The index of the first closed coordinate of the leg.
+	GeometryIndexEnd   int `json:"geometry_index_end"`   // The index of the last closed coordinate of the leg.
 }
 
 // Step represents a single step in a leg of a route, containing maneuver instructions and distance/duration.
 type Step struct {
-	Distance      float64        `json:"distance"`      // The distance for this step in meters.
-	Duration      float64        `json:"duration"`      // The estimated travel time for this step in seconds.
-	Geometry      string         `json:"geometry"`      // An encoded polyline string or GeoJSON LineString representing the step geometry.
-	Name          string         `json:"name"`          // The name of the road or path used in the step.
-	Maneuver      Maneuver       `json:"maneuver"`      // The maneuver required to move from this step to the next.
-	Mode          string         `json:"mode"`          // The travel mode of the step.
-	Weight        float64        `json:"weight"`        // Similar to duration but includes additional factors like traffic.
-	Intersections []Intersection `json:"intersections"` // An array of Intersection objects.
+	Distance      float64        `json:"distance"`               // The distance for this step in meters.
+	Duration      float64        `json:"duration"`               // The estimated travel time for this step in seconds.
+	Geometry      string         `json:"geometry"`               // The encoded polyline of the step, empty for GeoJSON geometries.
+	Name          string         `json:"name"`                   // The name of the road or path used in the step.
+	Ref           string         `json:"ref,omitempty"`          // The reference number or code of the road, e.g. "I 5".
+	Destinations  string         `json:"destinations,omitempty"` // The destinations of the road, e.g. "I 5 North: Los Angeles".
+	Exits         string         `json:"exits,omitempty"`        // The exit numbers or names of the road.
+	Maneuver      Maneuver       `json:"maneuver"`               // The maneuver required to move from this step to the next.
+	Mode          string         `json:"mode"`                   // The travel mode of the step.
+	Weight        float64        `json:"weight"`                 // Similar to duration but includes additional factors like traffic.
+	Intersections []Intersection `json:"intersections"`          // An array of Intersection objects.
+
+	VoiceInstructions  []VoiceInstruction  `json:"voiceInstructions,omitempty"`  // The spoken instructions of the step, requested with VoiceInstructions.
+	BannerInstructions []BannerInstruction `json:"bannerInstructions,omitempty"` // The visual instructions of the step, requested with BannerInstructions.
+
+	Coordinates Coordinates `json:"-"` // The coordinates of the step geometry, decoded regardless of the requested Geometries.
+}
+
+// UnmarshalJSON decodes the step, reading a GeoJSON geometry into Coordinates
+func (s *Step) UnmarshalJSON(data []byte) error {
+	type step Step
+	decoded := struct {
+		*step
+		Geometry lineGeometry `json:"geometry"`
+	}{step: (*step)(s)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	s.Geometry, s.Coordinates = decoded.Geometry.polyline, decoded.Geometry.coordinates
+	return nil
+}
+
+// MarshalJSON encodes the geometry as the polyline, or as a GeoJSON LineString when there is none
+func (s Step) MarshalJSON() ([]byte, error) {
+	type step Step
+	return json.Marshal(struct {
+		step
+		Geometry lineGeometry `json:"geometry"`
+	}{step(s), lineGeometry{s.Geometry, s.Coordinates}})
+}
+
+// decode fills Coordinates from the encoded polyline
+func (s *Step) decode(precision int) error {
+	coordinates, err := decodeLine(s.Geometry, s.Coordinates, precision)
+	if err != nil {
+		return err
+	}
+	s.Coordinates = coordinates
+	return nil
 }
 
 // Maneuver contains information about the required maneuver for a step, including type and bearing.
@@ -53,15 +257,17 @@ type Maneuver struct {
 	Type          string    `json:"type"`           // A string signifying the type of maneuver. Example: "turn".
 	Modifier      string    `json:"modifier"`       // An additional modifier to provide more detail. Example: "left".
 	Instruction   string    `json:"instruction"`    // Verbal instruction for the maneuver.
+	Exit          int       `json:"exit,omitempty"` // The exit to take on a roundabout or rotary maneuver, e.g. 3 for the 3rd exit.
 }
 
 // Annotation contains additional details about each point along the route leg.
 type DirectionsAnnotation struct {
-	Distance   []float64  `json:"distance"`   // Array of distances between each pair of coordinates.
-	Duration   []float64  `json:"duration"`   // Array of expected travel times from each coordinate to the next.
-	Speed      []float64  `json:"speed"`      // Array of travel speeds.
-	Congestion []string   `json:"congestion"` // Array of congestion levels.
-	Maxspeed   []Maxspeed `json:"maxspeed"`
+	Distance          []float64  `json:"distance"`           // Array of distances between each pair of coordinates.
+	Duration          []float64  `json:"duration"`           // Array of expected travel times from each coordinate to the next.
+	Speed             []float64  `json:"speed"`              // Array of travel speeds.
+	Congestion        []string   `json:"congestion"`         // Array of congestion levels.
+	CongestionNumeric []*int     `json:"congestion_numeric"` // Array of congestion levels from 0 to 100, nil where unknown.
+	Maxspeed          []Maxspeed `json:"maxspeed"`           // Array of speed limits, see Maxspeed.
 }
 
 // Admin represents administrative region information.
@@ -86,25 +292,48 @@ type BannerInstruction struct {
 
 // Instruction contains the details of a navigation instruction.
 type Instruction struct {
-	Text       string      `json:"text"`       // The instruction text.
-	Type       string      `json:"type"`       // The type of maneuver.
-	Modifier   string      `json:"modifier"`   // An additional modifier to provide more detail.
-	Components []Component `json:"components"` // Components of the instruction.
+	Text       string      `json:"text"`              // The instruction text.
+	Type       string      `json:"type"`              // The type of maneuver.
+	Modifier   string      `json:"modifier"`          // An additional modifier to provide more detail.
+	Components []Component `json:"components"`        // Components of the instruction.
+	Degrees    float64     `json:"degrees,omitempty"` // The degrees to travel around a roundabout, from its entry to the exit.
 }
 
 // Component represents a part of the instruction, useful for highlighting parts of the text.
 type Component struct {
-	Text string `json:"text"` // The component text.
-	Type string `json:"type"` // The type of component, e.g., "text" or "icon".
+	Text         string   `json:"text"`                    // The component text.
+	Type         string   `json:"type"`                    // The type of component, e.g., "text", "icon", "exit-number" or "lane".
+	Abbr         string   `json:"abbr,omitempty"`          // An abbreviation of the text, e.g. "N" for "North".
+	AbbrPriority int      `json:"abbr_priority,omitempty"` // The order in which to abbreviate the components when space is short, lowest first.
+	Directions   []string `json:"directions,omitempty"`    // The turns of a "lane" component, e.g. "left", "straight".
+	Active       bool     `json:"active,omitempty"`        // Whether the "lane" component can be taken to follow the route.
 }
 
 // Intersection represents an intersection along a step.
 type Intersection struct {
-	Location []float64 `json:"location"`      // The location of the intersection [longitude, latitude].
-	Bearings []int     `json:"bearings"`      // The bearings at the intersection, in degrees.
-	Entry    []bool    `json:"entry"`         // A boolean flag indicating the availability of the corresponding bearing.
-	In       int       `json:"in,omitempty"`  // The index into the bearings/entry array that denotes the incoming bearing to the intersection.
-	Out      int       `json:"out,omitempty"` // The index into the bearings/entry array that denotes the outgoing bearing from the intersection.
+	Location []float64 `json:"location"`          // The location of the intersection [longitude, latitude].
+	Bearings []int     `json:"bearings"`          // The bearings at the intersection, in degrees.
+	Entry    []bool    `json:"entry"`             // A boolean flag indicating the availability of the corresponding bearing.
+	In       int       `json:"in,omitempty"`      // The index into the bearings/entry array that denotes the incoming bearing to the intersection.
+	Out      int       `json:"out,omitempty"`     // The index into the bearings/entry array that denotes the outgoing bearing from the intersection.
+	Lanes    []Lane    `json:"lanes,omitempty"`   // The lanes of the incoming road, left to right, when known.
+	Classes  []string  `json:"classes,omitempty"` // The classes of the outgoing road, e.g. "toll", "tunnel".
+}
+
+// Coordinate returns the location of the intersection, false when missing
+func (i *Intersection) Coordinate() (Coordinate, bool) {
+	if len(i.Location) < 2 {
+		return Coordinate{}, false
+	}
+	return Coordinate{Lat: i.Location[1], Lng: i.Location[0]}, true
+}
+
+// Lane is a lane of the road entering an Intersection, for lane guidance.
+type Lane struct {
+	Valid           bool     `json:"valid"`                      // Whether the lane can be taken to follow the route.
+	Active          bool     `json:"active,omitempty"`           // Whether the lane is the preferred one to follow the route.
+	ValidIndication string   `json:"valid_indication,omitempty"` // The indication to follow the route when the lane is valid, e.g. "straight".
+	Indications     []string `json:"indications"`                // The turns the lane allows, e.g. "left", "straight".
 }
 
 type ViaWaypoint struct {
@@ -113,7 +342,16 @@ type ViaWaypoint struct {
 	GeometryIndex     int     `json:"geometry_index"`
 }
 
+// Maxspeed is the speed limit of a segment, requested with AnnotationMaxspeed. Mapbox reports segments without
+// a limit as None (e.g. German autobahns) and segments whose limit it doesn't know as Unknown, both without Speed.
 type Maxspeed struct {
-	Speed int    `json:"speed"`
-	Unit  string `json:"unit"`
+	Speed   int    `json:"speed,omitempty"`
+	Unit    string `json:"unit,omitempty"` // "km/h" or "mph"
+	Unknown bool   `json:"unknown,omitempty"`
+	None    bool   `json:"none,omitempty"`
+}
+
+// Known reports whether the segment has a speed limit value
+func (m Maxspeed) Known() bool {
+	return !m.Unknown && !m.None && m.Speed > 0
 }
@@ -1,8 +1,15 @@
 package mapbox
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"testing"
+	"time"
 )
 
 func checkforwardDirectionsRequestURL(t *testing.T, req *DirectionsRequest, expectedURL string) {
@@ -48,3 +55,544 @@ func TestForwardDirectionsURLEncoding(t *testing.T) {
 		SnappingIncludeStaticClosures: &trueVal,
 	}, `/directions/v5/mapbox/driving-traffic/-117.306786,33.122508;-117.193443,32.73381?alternatives=true&annotations=distance%2Cduration&approaches=unrestricted&avoid_maneuver_radius=1&banner_instructions=true&continue_straight=true&exclude=unpaved%2Ccash_only_tolls&geometries=geojson&include=hov2%2Chot&language=en&overview=full&roundabout_exits=true&snapping_include_closures=true&snapping_include_static_closures=true&steps=true&voice_instructions=true&voice_units=metric&waypoint_names=wp1%3Bwp2&waypoint_targets=wpt1%3Bwpt2&waypoints_per_route=true`)
 }
+
+func TestDirectionsRequestWaypointsValidation(t *testing.T) {
+	coordinates := Coordinates{
+		Coordinate{Lat: 33.122508, Lng: -117.306786},
+		Coordinate{Lat: 33.0, Lng: -117.25},
+		Coordinate{Lat: 32.733810, Lng: -117.193443},
+	}
+
+	tests := []struct {
+		waypoints DirectionWaypoints
+		err       bool
+	}{
+		{nil, false},
+		{NewDirectionWaypoints(0, 2), false},
+		{NewDirectionWaypoints(0, 1, 2), false},
+		{NewDirectionWaypoints(1, 2), true},
+		{NewDirectionWaypoints(0, 1), true},
+		{NewDirectionWaypoints(0, 3), true},
+		{NewDirectionWaypoints(0, 2, 2), true},
+		{DirectionWaypoints{"0", "last"}, true},
+	}
+
+	for _, test := range tests {
+		req := &DirectionsRequest{Profile: ProfileDriving, Coordinates: coordinates, Waypoints: test.waypoints}
+		if err := req.validate(); (err != nil) != test.err {
+			t.Errorf("%v: expected error %v, got %v", test.waypoints, test.err, err)
+		}
+	}
+
+	falseVal := false
+	checkforwardDirectionsRequestURL(t, &DirectionsRequest{
+		Profile:          ProfileDriving,
+		Coordinates:      coordinates,
+		ContinueStraight: &falseVal,
+		Waypoints:        NewDirectionWaypoints(0, 2),
+	}, `/directions/v5/mapbox/driving/-117.306786,33.122508;-117.25,33;-117.193443,32.73381?continue_straight=false&geometries=polyline6&waypoints=0%3B2`)
+}
+
+func TestDirectionsRequestWaypointNamesValidation(t *testing.T) {
+	coordinates := Coordinates{
+		Coordinate{Lat: 33.122508, Lng: -117.306786},
+		Coordinate{Lat: 33.0, Lng: -117.25},
+		Coordinate{Lat: 32.733810, Lng: -117.193443},
+	}
+
+	tests := []struct {
+		waypoints DirectionWaypoints
+		names     WaypointNames
+		err       bool
+	}{
+		{nil, WaypointNames{"home", "stop", "office"}, false},
+		{nil, WaypointNames{"home", "office"}, true},
+		{NewDirectionWaypoints(0, 2), WaypointNames{"home", "office"}, false},
+		{NewDirectionWaypoints(0, 2), WaypointNames{"home", "stop", "office"}, true},
+	}
+
+	for _, test := range tests {
+		req := &DirectionsRequest{Profile: ProfileDriving, Coordinates: coordinates, Waypoints: test.waypoints, WaypointNames: test.names}
+		if err := req.validate(); (err != nil) != test.err {
+			t.Errorf("%v %v: expected error %v, got %v", test.waypoints, test.names, test.err, err)
+		}
+	}
+
+	var response DirectionsResponse
+	if err := json.Unmarshal([]byte(`{"code":"Ok","waypoints":[{"name":"home","location":[-117.306786,33.122508]}]}`), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(response.Waypoints) != 1 || response.Waypoints[0].Name != "home" {
+		t.Errorf("expected the waypoint name, got %+v", response.Waypoints)
+	}
+}
+
+func TestDirectionsGeometries(t *testing.T) {
+	coordinates := Coordinates{
+		Coordinate{Lat: 33.122508, Lng: -117.306786},
+		Coordinate{Lat: 32.733810, Lng: -117.193443},
+	}
+	polyline6 := EncodePolyline(coordinates, Polyline6Precision)
+
+	tests := []struct {
+		geometries Geometries
+		body       string
+		query      string
+	}{
+		{"", `{"code":"Ok","routes":[{"geometry":"` + polyline6 + `"}]}`, "geometries=polyline6"},
+		{GeometriesPolyline, `{"code":"Ok","routes":[{"geometry":"` + EncodePolyline(coordinates, PolylinePrecision) + `"}]}`, "geometries=polyline"},
+		{GeometriesGeoJSON, `{"code":"Ok","routes":[{"geometry":{"type":"LineString","coordinates":[[-117.306786,33.122508],[-117.193443,32.73381]]}}]}`, "geometries=geojson"},
+	}
+
+	for _, test := range tests {
+		client, requests := mockClient(&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(test.body)),
+		})
+		captured := make(chan *http.Request, 1)
+		go func() { captured <- <-requests }()
+
+		response, err := client.Directions(context.Background(), &DirectionsRequest{
+			Profile:     ProfileDriving,
+			Coordinates: coordinates,
+			Geometries:  test.geometries,
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if query := (<-captured).URL.RawQuery; query != test.query {
+			t.Errorf("expected query %q, got %q", test.query, query)
+		}
+		assertCoordinates(t, coordinates, response.Routes[0].Coordinates, 1e-5)
+	}
+}
+
+func TestDirectionsSteps(t *testing.T) {
+	coordinates := Coordinates{
+		Coordinate{Lat: 33.122508, Lng: -117.306786},
+		Coordinate{Lat: 32.733810, Lng: -117.193443},
+	}
+	step := `{"geometry":"` + EncodePolyline(coordinates, PolylinePrecision) + `","name":"San Diego Freeway","ref":"I 5","destinations":"I 5 South: San Diego","exits":"44","mode":"driving"}`
+
+	client, requests := mockClient(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"code":"Ok","routes":[{"legs":[{"steps":[` + step + `]}]}]}`)),
+	})
+	go func() { <-requests }()
+
+	steps := true
+	response, err := client.Directions(context.Background(), &DirectionsRequest{
+		Profile:     ProfileDriving,
+		Coordinates: coordinates,
+		Geometries:  GeometriesPolyline,
+		Steps:       &steps,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	decoded := response.Routes[0].Legs[0].Steps[0]
+	if decoded.Name != "San Diego Freeway" || decoded.Ref != "I 5" || decoded.Destinations != "I 5 South: San Diego" || decoded.Exits != "44" || decoded.Mode != "driving" {
+		t.Errorf("unexpected step %+v", decoded)
+	}
+	assertCoordinates(t, coordinates, decoded.Coordinates, 1e-5)
+
+	voice := true
+	if _, err := client.DirectionsURL(context.Background(), &DirectionsRequest{
+		Profile:           ProfileDriving,
+		Coordinates:       coordinates,
+		VoiceInstructions: &voice,
+	}); err == nil {
+		t.Errorf("expected voice instructions without steps to be rejected")
+	}
+}
+
+func TestStepIntersections(t *testing.T) {
+	var step Step
+	body := `{"intersections":[{"location":[-117.306786,33.122508],"bearings":[0,90,180],"entry":[false,true,true],"in":2,"out":1,
+		"classes":["toll"],"lanes":[{"valid":false,"indications":["left"]},{"valid":true,"active":true,"valid_indication":"straight","indications":["straight","right"]}]}]}`
+	if err := json.Unmarshal([]byte(body), &step); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(step.Intersections) != 1 {
+		t.Fatalf("expected 1 intersection, got %v", len(step.Intersections))
+	}
+	intersection := step.Intersections[0]
+	if coordinate, ok := intersection.Coordinate(); !ok || coordinate.Lat != 33.122508 || coordinate.Lng != -117.306786 {
+		t.Errorf("unexpected location %v", coordinate)
+	}
+	if intersection.In != 2 || intersection.Out != 1 || len(intersection.Entry) != 3 || !equalStrings(intersection.Classes, []string{"toll"}) {
+		t.Errorf("unexpected intersection %+v", intersection)
+	}
+	if len(intersection.Lanes) != 2 || intersection.Lanes[0].Valid || !intersection.Lanes[1].Active || intersection.Lanes[1].ValidIndication != "straight" || !equalStrings(intersection.Lanes[1].Indications, []string{"straight", "right"}) {
+		t.Errorf("unexpected lanes %+v", intersection.Lanes)
+	}
+}
+
+func TestDirectionsLocalization(t *testing.T) {
+	client, _ := NewClient(&MapboxConfig{APIKey: "test"})
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.733810, Lng: -117.193443}}
+	steps := true
+
+	u, err := client.DirectionsURL(context.Background(), &DirectionsRequest{
+		Profile:            ProfileDriving,
+		Coordinates:        coordinates,
+		Steps:              &steps,
+		VoiceInstructions:  &steps,
+		BannerInstructions: &steps,
+		Language:           "de",
+		VoiceUnits:         VoiceUnitsImperial,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	query := u.Query()
+	if query.Get("language") != "de" || query.Get("voice_units") != "imperial" || query.Get("voice_instructions") != "true" || query.Get("banner_instructions") != "true" {
+		t.Errorf("unexpected localization parameters %v", query)
+	}
+
+	for _, req := range []*DirectionsRequest{
+		{Profile: ProfileDriving, Coordinates: coordinates, VoiceUnits: "miles"},
+		{Profile: ProfileDriving, Coordinates: coordinates, Language: "german"},
+		{Profile: ProfileDriving, Coordinates: coordinates, Language: "de,en"},
+	} {
+		if err := req.validate(); err == nil {
+			t.Errorf("expected a localization validation error for %+v", req)
+		}
+	}
+
+	var step Step
+	body := `{"voiceInstructions":[{"distanceAlongGeometry":120,"announcement":"In 400 Fuß rechts abbiegen"}],
+		"bannerInstructions":[{"distanceAlongGeometry":120,"primary":{"text":"Main Street","type":"turn","modifier":"right"}}]}`
+	if err := json.Unmarshal([]byte(body), &step); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(step.VoiceInstructions) != 1 || step.VoiceInstructions[0].Announcement != "In 400 Fuß rechts abbiegen" {
+		t.Errorf("unexpected voice instructions %+v", step.VoiceInstructions)
+	}
+	if len(step.BannerInstructions) != 1 || step.BannerInstructions[0].Primary.Modifier != "right" {
+		t.Errorf("unexpected banner instructions %+v", step.BannerInstructions)
+	}
+}
+
+func TestStepRoundaboutBanner(t *testing.T) {
+	var step Step
+	body := `{"maneuver":{"type":"roundabout","modifier":"right","exit":3,"instruction":"Enter the roundabout and take the 3rd exit"},
+		"bannerInstructions":[{"distanceAlongGeometry":80,"primary":{"text":"North Main Street","type":"roundabout","modifier":"right","degrees":270,
+			"components":[{"text":"North Main Street","type":"text","abbr":"N Main St","abbr_priority":0},{"text":"3","type":"exit-number"}]},
+			"secondary":{"text":"","type":"turn","components":[{"text":"","type":"lane","directions":["left","straight"],"active":true}]}}]}`
+	if err := json.Unmarshal([]byte(body), &step); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if step.Maneuver.Exit != 3 || step.Maneuver.Type != "roundabout" {
+		t.Errorf("unexpected maneuver %+v", step.Maneuver)
+	}
+	primary := step.BannerInstructions[0].Primary
+	if primary.Degrees != 270 || len(primary.Components) != 2 || primary.Components[0].Abbr != "N Main St" || primary.Components[1].Type != "exit-number" {
+		t.Errorf("unexpected primary instruction %+v", primary)
+	}
+	lane := step.BannerInstructions[0].Secondary.Components[0]
+	if !lane.Active || !equalStrings(lane.Directions, []string{"left", "straight"}) {
+		t.Errorf("unexpected lane component %+v", lane)
+	}
+
+	exits := true
+	req := &DirectionsRequest{Profile: ProfileDriving, Coordinates: Coordinates{{Lat: 33.1, Lng: -117.3}, {Lat: 32.7, Lng: -117.2}}, RoundaboutExits: &exits}
+	if err := req.validate(); err == nil {
+		t.Errorf("expected roundabout exits without steps to be rejected")
+	}
+	req.Steps = &exits
+	if err := req.validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestRouteETASpread(t *testing.T) {
+	var response DirectionsResponse
+	body := `{"code":"Ok","routes":[
+		{"duration":1680.5,"duration_typical":1200,"legs":[{"duration":1680.5,"duration_typical":1200}]},
+		{"duration":900}
+	]}`
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	typical, current := response.Routes[0].ETASpread()
+	if typical != 20*time.Minute || current != 28*time.Minute+500*time.Millisecond {
+		t.Errorf("unexpected spread %v %v", typical, current)
+	}
+	if leg := response.Routes[0].Legs[0]; leg.DurationTypical != 1200 {
+		t.Errorf("unexpected leg typical duration %v", leg.DurationTypical)
+	}
+
+	typical, current = response.Routes[1].ETASpread()
+	if typical != 0 || current != 15*time.Minute {
+		t.Errorf("unexpected spread without traffic %v %v", typical, current)
+	}
+}
+
+func TestDirectionsRequestAnnotationsValidation(t *testing.T) {
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.733810, Lng: -117.193443}}
+
+	tests := []struct {
+		profile     Profile
+		annotations Annotations
+		overview    Overview
+		valid       bool
+	}{
+		{ProfileDriving, nil, OverviewSimplified, true},
+		{ProfileDriving, Annotations{AnnotationDuration, AnnotationDistance, AnnotationSpeed}, "", true},
+		{ProfileWalking, Annotations{AnnotationDistance}, OverviewFull, true},
+		{ProfileDrivingTraffic, Annotations{AnnotationCongestion}, "", true},
+		{ProfileDrivingTraffic, Annotations{AnnotationCongestion}, OverviewFull, true},
+		{ProfileDriving, Annotations{AnnotationCongestion}, "", false},
+		{ProfileCycling, Annotations{AnnotationDuration, AnnotationCongestion}, OverviewFull, false},
+		{ProfileDrivingTraffic, Annotations{AnnotationCongestionNumeric}, "", true},
+		{ProfileDriving, Annotations{AnnotationCongestionNumeric}, "", false},
+		{ProfileWalking, Annotations{AnnotationCongestionNumeric}, "", false},
+		{ProfileDriving, Annotations{AnnotationMaxspeed}, "", true},
+		{ProfileDrivingTraffic, Annotations{AnnotationMaxspeed, AnnotationCongestion}, "", true},
+		{ProfileWalking, Annotations{AnnotationMaxspeed}, "", false},
+		{ProfileCycling, Annotations{AnnotationSpeed, AnnotationMaxspeed}, "", false},
+		{ProfileCycling, Annotations{AnnotationDuration, AnnotationDistance, AnnotationSpeed}, "", true},
+		// overview is upgraded to full
+		{ProfileDriving, Annotations{AnnotationDuration}, OverviewSimplified, true},
+		{ProfileDrivingTraffic, Annotations{AnnotationCongestion}, OverviewFalse, true},
+	}
+
+	for _, test := range tests {
+		req := &DirectionsRequest{Profile: test.profile, Coordinates: coordinates, Annotations: test.annotations, Overview: test.overview}
+		if err := req.validate(); (err == nil) != test.valid {
+			t.Errorf("expected valid %v for %v %v overview=%q, got %v", test.valid, test.profile, test.annotations, test.overview, err)
+		}
+	}
+}
+
+func TestDirectionsResponseAlternatives(t *testing.T) {
+	response := &DirectionsResponse{Routes: []Route{
+		{Duration: 1200, Distance: 15000},
+		{Duration: 900, Distance: 18000},
+		{Duration: 1500, Distance: 12000},
+		{Duration: 900, Distance: 16000},
+	}}
+
+	if fastest, ok := response.Fastest(); !ok || fastest != &response.Routes[1] {
+		t.Errorf("expected the second route, got %+v", fastest)
+	}
+	if shortest, ok := response.Shortest(); !ok || shortest != &response.Routes[2] {
+		t.Errorf("expected the third route, got %+v", shortest)
+	}
+	var durations []float64
+	for _, route := range response.RankByDuration() {
+		durations = append(durations, route.Duration)
+	}
+	if expected := []float64{900, 900, 1200, 1500}; !equalFloats(durations, expected) || response.Routes[0].Duration != 1200 {
+		t.Errorf("expected %v with the routes untouched, got %v", expected, durations)
+	}
+
+	empty := &DirectionsResponse{}
+	if _, ok := empty.Fastest(); ok {
+		t.Errorf("expected no fastest route")
+	}
+	if _, ok := empty.Shortest(); ok {
+		t.Errorf("expected no shortest route")
+	}
+	if len(empty.RankByDuration()) != 0 {
+		t.Errorf("expected no ranked routes")
+	}
+}
+
+func TestDirectionsDuplicateCoordinates(t *testing.T) {
+	a, b, c := Coordinate{Lat: 33.1, Lng: -117.3}, Coordinate{Lat: 33.2, Lng: -117.2}, Coordinate{Lat: 33.3, Lng: -117.1}
+
+	req := &DirectionsRequest{Profile: ProfileDriving, Coordinates: Coordinates{a, b, b, c}}
+	if _, _, err := directionsQuery(nil, req); err == nil {
+		t.Errorf("expected an error for the duplicate coordinates")
+	}
+
+	req = &DirectionsRequest{
+		Profile:            ProfileDriving,
+		Coordinates:        Coordinates{a, b, b, b, c},
+		Approaches:         Approaches{ApproachUnrestricted, ApproachCurb, ApproachUnrestricted, ApproachUnrestricted, ApproachCurb},
+		Waypoints:          NewDirectionWaypoints(0, 2, 3, 4),
+		WaypointNames:      WaypointNames{"home", "shop", "shop again", "work"},
+		CollapseDuplicates: true,
+	}
+	relPath, query, err := directionsQuery(nil, req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expected := "directions/v5/mapbox/driving/" + (Coordinates{a, b, c}).WGS84Format(); relPath != expected {
+		t.Errorf("expected %v, got %v", expected, relPath)
+	}
+	if approaches := query.Get("approaches"); approaches != "unrestricted;curb;curb" {
+		t.Errorf("unexpected approaches %v", approaches)
+	}
+	if waypoints, names := query.Get("waypoints"), query.Get("waypoint_names"); waypoints != "0;1;2" || names != "home;shop;work" {
+		t.Errorf("unexpected waypoints %v named %v", waypoints, names)
+	}
+	if len(req.Coordinates) != 5 {
+		t.Errorf("expected the request to be left untouched, got %v", req.Coordinates)
+	}
+}
+
+func TestDirectionsRequestDimensions(t *testing.T) {
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.733810, Lng: -117.193443}}
+
+	tests := []struct {
+		req   DirectionsRequest
+		query string
+		valid bool
+	}{
+		{DirectionsRequest{Profile: ProfileDriving}, "", true},
+		{DirectionsRequest{Profile: ProfileDriving, MaxHeight: 4.1, MaxWidth: 2.55, MaxWeight: 40}, "max_height=4.1&max_weight=40&max_width=2.55", true},
+		{DirectionsRequest{Profile: ProfileDrivingTraffic, MaxWeight: 7.5}, "max_weight=7.5", true},
+		{DirectionsRequest{Profile: ProfileDriving, MaxHeight: -1}, "", false},
+		{DirectionsRequest{Profile: ProfileDriving, MaxWidth: 11}, "", false},
+		{DirectionsRequest{Profile: ProfileDriving, MaxWeight: 101}, "", false},
+		{DirectionsRequest{Profile: ProfileWalking, MaxHeight: 2}, "", false},
+		{DirectionsRequest{Profile: ProfileCycling, MaxWeight: 1}, "", false},
+	}
+
+	for _, test := range tests {
+		req := test.req
+		req.Coordinates = coordinates
+		_, query, err := directionsQuery(nil, &req)
+		if (err == nil) != test.valid {
+			t.Errorf("expected valid %v for %+v, got %v", test.valid, test.req, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		dimensions := url.Values{}
+		for _, key := range []string{"max_height", "max_width", "max_weight"} {
+			if value := query.Get(key); value != "" {
+				dimensions.Set(key, value)
+			}
+		}
+		if dimensions.Encode() != test.query {
+			t.Errorf("expected %q, got %q", test.query, dimensions.Encode())
+		}
+	}
+}
+
+func TestDirectionsMaxspeedAnnotation(t *testing.T) {
+	checkforwardDirectionsRequestURL(t, &DirectionsRequest{
+		Profile:     ProfileDriving,
+		Coordinates: Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.73381, Lng: -117.193443}},
+		Annotations: Annotations{AnnotationMaxspeed},
+	}, `/directions/v5/mapbox/driving/-117.306786,33.122508;-117.193443,32.73381?annotations=maxspeed&geometries=polyline6&overview=full`)
+
+	var annotation DirectionsAnnotation
+	data := `{"maxspeed":[{"speed":56,"unit":"km/h"},{"unknown":true},{"none":true}]}`
+	if err := json.Unmarshal([]byte(data), &annotation); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []Maxspeed{{Speed: 56, Unit: "km/h"}, {Unknown: true}, {None: true}}
+	if len(annotation.Maxspeed) != len(expected) {
+		t.Fatalf("expected %v speeds, got %v", len(expected), annotation.Maxspeed)
+	}
+	for i, speed := range annotation.Maxspeed {
+		if speed != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], speed)
+		}
+		if speed.Known() != (i == 0) {
+			t.Errorf("unexpected known %v for %+v", speed.Known(), speed)
+		}
+	}
+}
+
+func TestDirectionsClosures(t *testing.T) {
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.733810, Lng: -117.193443}}
+	include, exclude := true, false
+
+	tests := []struct {
+		req   DirectionsRequest
+		query string
+		valid bool
+	}{
+		{DirectionsRequest{Profile: ProfileDrivingTraffic}, "", true},
+		{DirectionsRequest{Profile: ProfileDrivingTraffic, SnappingIncludeClosures: &exclude}, "false", true},
+		{DirectionsRequest{Profile: ProfileDrivingTraffic, SnappingIncludeClosures: &include}, "true", true},
+		{DirectionsRequest{Profile: ProfileDriving, SnappingIncludeClosures: &include}, "", false},
+		{DirectionsRequest{Profile: ProfileWalking, SnappingIncludeStaticClosures: &exclude}, "", false},
+	}
+	for _, test := range tests {
+		req := test.req
+		req.Coordinates = coordinates
+		_, query, err := directionsQuery(nil, &req)
+		if (err == nil) != test.valid {
+			t.Errorf("expected valid %v for %+v, got %v", test.valid, test.req, err)
+			continue
+		}
+		if err == nil && query.Get("snapping_include_closures") != test.query {
+			t.Errorf("expected %q, got %q", test.query, query.Get("snapping_include_closures"))
+		}
+	}
+
+	var route Route
+	data := `{"duration":600,"legs":[{"duration":300},{"duration":300,"closures":[{"geometry_index_start":4,"geometry_index_end":9}]}]}`
+	if err := json.Unmarshal([]byte(data), &route); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expected := (Closure{GeometryIndexStart: 4, GeometryIndexEnd: 9}); len(route.Legs[1].Closures) != 1 || route.Legs[1].Closures[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, route.Legs[1].Closures)
+	}
+	if !route.HasClosures() || (&Route{Legs: route.Legs[:1]}).HasClosures() {
+		t.Errorf("unexpected closures of %+v", route.Legs)
+	}
+}
+
+func TestRouteBetweenAddresses(t *testing.T) {
+	geocoded := func(features string) *http.Response {
+		return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[` + features + `]}`))}
+	}
+	client, requests := mockClient(
+		geocoded(`{"id":"address.1","relevance":1,"center":[-117.3,33.1],"routable_points":{"points":[{"name":"default_routable_point","coordinates":[-117.31,33.12]}]}}`),
+		geocoded(`{"id":"address.2","relevance":0.9,"center":[-117.19,32.73]}`),
+		&http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"code":"Ok","routes":[{"duration":1800,"distance":40000}]}`))},
+	)
+	urls := make(chan string, 3)
+	go func() {
+		for r := range requests {
+			urls <- r.URL.RequestURI()
+		}
+	}()
+	defer close(requests)
+
+	route, err := client.RouteBetweenAddresses(context.Background(), "home", "work", ProfileDriving, &RouteBetweenAddressesOptions{
+		Geocode: &ForwardGeocodeRequest{Endpoint: EndpointPlaces, Country: "us"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if route.From.ID != "address.1" || route.To.ID != "address.2" || len(route.Directions.Routes) != 1 {
+		t.Errorf("unexpected route %+v", route)
+	}
+	<-urls
+	<-urls
+	if expected := `/directions/v5/mapbox/driving/-117.31,33.12;-117.19,32.73?geometries=polyline6`; <-urls != expected {
+		t.Errorf("expected the route between the routable points %v", expected)
+	}
+
+	client, requests = mockClient(
+		geocoded(`{"id":"address.1","relevance":0.9,"place_name":"Main St, Springfield, Illinois","center":[-89.6,39.8]}`+
+			`,{"id":"address.3","relevance":0.88,"place_name":"Main St, Springfield, Missouri","center":[-93.3,37.2]}`),
+		geocoded(``),
+	)
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+
+	if _, err := client.RouteBetweenAddresses(context.Background(), "main st springfield", "work", ProfileDriving, &RouteBetweenAddressesOptions{RejectAmbiguous: true}); !errors.Is(err, ErrAmbiguousAddress) {
+		t.Errorf("expected ErrAmbiguousAddress, got %v", err)
+	}
+	if _, err := client.RouteBetweenAddresses(context.Background(), "nowhere", "work", ProfileDriving, nil); !errors.Is(err, ErrNoResults) {
+		t.Errorf("expected ErrNoResults, got %v", err)
+	}
+}
@@ -1,10 +1,31 @@
 package mapbox
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrNoResults is returned by single result helpers when Mapbox returns no features.
+var ErrNoResults = errors.New("no results")
+
+// ErrAmbiguousAddress is returned by RouteBetweenAddresses when an address matches several features and
+// RejectAmbiguous is set.
+var ErrAmbiguousAddress = errors.New("ambiguous address")
+
+// ErrCircuitOpen is returned while the client's circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
 
 type MapboxError struct {
 	StatusCode int    `json:"status_code"`
 	Message    string `json:"error"`
+
+	// Details holds any structured error fields besides the message, e.g. the code or suggestions on a 422
+	Details map[string]interface{} `json:"details,omitempty"`
+
+	// Body holds the start of a response that isn't JSON, e.g. the HTML page of a gateway or firewall
+	Body string `json:"body,omitempty"`
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -19,3 +40,73 @@ func NewMapboxError(statusCode int, message string) MapboxError {
 func (e MapboxError) Error() string {
 	return fmt.Sprintf("api error(%v): %v", e.StatusCode, e.Message)
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+// MatrixLimitError is returned when a matrix request has more coordinates than the profile allows.
+// Larger matrices can be built with Client.LargeMatrix.
+type MatrixLimitError struct {
+	Profile Profile
+	Max     int
+	Actual  int
+}
+
+func (e MatrixLimitError) Error() string {
+	return fmt.Sprintf("matrix %v supports at most %v coordinates, got %v. Use LargeMatrix for larger matrices", e.Profile, e.Max, e.Actual)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ScopeError is returned when the access token lacks a scope the endpoint requires, e.g. a public token used for
+// the tokens API. It unwraps to the 403 MapboxError when Mapbox rejected the request.
+type ScopeError struct {
+	Required []string // the missing scopes, e.g. "tokens:write"
+	Granted  []string // the token scopes when known, e.g. from ValidateToken
+	Err      error
+}
+
+func (e ScopeError) Error() string {
+	if len(e.Granted) == 0 {
+		return fmt.Sprintf("access token lacks the scopes %v", strings.Join(e.Required, ", "))
+	}
+	return fmt.Sprintf("access token lacks the scopes %v, it has %v", strings.Join(e.Required, ", "), strings.Join(e.Granted, ", "))
+}
+
+func (e ScopeError) Unwrap() error {
+	return e.Err
+}
+
+// scopePattern matches the scopes named in a 403 message, e.g. "This endpoint requires a token with tokens:write scope."
+var scopePattern = regexp.MustCompile(`\b[a-z]+:[a-z-]+\b`)
+
+// scopeError returns a ScopeError when the 403 error names the scopes the token lacks
+func scopeError(err MapboxError) (ScopeError, bool) {
+	if !strings.Contains(strings.ToLower(err.Message), "scope") {
+		return ScopeError{}, false
+	}
+	required := scopePattern.FindAllString(err.Message, -1)
+	if len(required) == 0 {
+		return ScopeError{}, false
+	}
+	return ScopeError{Required: required, Err: err}, true
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// PartialDecodeError is returned along with the response, in MapboxConfig LenientDecoding mode, when some features
+// of a geocoding or Search Box response failed to decode. The response holds the other features.
+type PartialDecodeError struct {
+	Indices []int   // positions of the failed features in the response
+	Errs    []error // decoding errors, one per index
+	Total   int     // features in the response
+}
+
+func (e PartialDecodeError) Error() string {
+	return fmt.Sprintf("failed to decode %v of %v features at %v. %v", len(e.Indices), e.Total, e.Indices, e.Errs[0])
+}
+
+// isPartialDecode reports whether err is a PartialDecodeError, the response then being usable
+func isPartialDecode(err error) bool {
+	var partialErr PartialDecodeError
+	return errors.As(err, &partialErr)
+}
@@ -0,0 +1,850 @@
+package mapbox
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Type returns the feature type encoded in the context ID, e.g. "postcode" for "postcode.8453667903266430"
+func (c *Context) Type() Type {
+	if i := strings.IndexByte(c.ID, '.'); i > 0 {
+		return Type(c.ID[:i])
+	}
+	return Type(c.ID)
+}
+
+// Coordinate returns the location of the feature, from its point geometry or center
+func (f *Feature) Coordinate() (Coordinate, bool) {
+	if f.Geometry != nil && f.Geometry.Type == "Point" && len(f.Geometry.Coordinates) >= 2 {
+		return Coordinate{Lat: f.Geometry.Coordinates[1], Lng: f.Geometry.Coordinates[0]}, true
+	}
+	if len(f.Center) >= 2 {
+		return Coordinate{Lat: f.Center[1], Lng: f.Center[0]}, true
+	}
+	return Coordinate{}, false
+}
+
+// RoutablePoint returns where to navigate to reach the feature, e.g. the street side of a warehouse: the Search Box
+// "default" routable point, or the first one, then the first geocoding routable point. Falls back to the feature
+// Coordinate when it has none, false when it has no location at all.
+func (f *Feature) RoutablePoint() (Coordinate, bool) {
+	if f.Properties != nil && f.Properties.Coordinates != nil && len(f.Properties.Coordinates.RoutablePoints) != 0 {
+		points := f.Properties.Coordinates.RoutablePoints
+		for _, point := range points {
+			if point.Name == "default" {
+				return point.Coordinate, true
+			}
+		}
+		return points[0].Coordinate, true
+	}
+	if f.RoutablePoints != nil {
+		for _, point := range f.RoutablePoints.Points {
+			if len(point.Coordinates) >= 2 {
+				return Coordinate{Lat: point.Coordinates[1], Lng: point.Coordinates[0]}, true
+			}
+		}
+	}
+	return f.Coordinate()
+}
+
+// BoundingBox returns the Bbox of the feature, [minLng, minLat, maxLng, maxLat], as a BoundingBox.
+// Returns false when the feature has no bbox or it is malformed.
+func (f *Feature) BoundingBox() (BoundingBox, bool) {
+	if len(f.Bbox) != 4 {
+		return BoundingBox{}, false
+	}
+	for _, v := range f.Bbox {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return BoundingBox{}, false
+		}
+	}
+
+	box := BoundingBox{
+		Min: Coordinate{Lat: f.Bbox[1], Lng: f.Bbox[0]},
+		Max: Coordinate{Lat: f.Bbox[3], Lng: f.Bbox[2]},
+	}
+	if box.Min.Lat > box.Max.Lat {
+		return BoundingBox{}, false
+	}
+	return box, true
+}
+
+// AsProximity returns the feature location to bias the next request, e.g. a POI search in a geocoded city.
+// Returns the zero Coordinate, which leaves the Proximity unset, when the feature has no location.
+func (f *Feature) AsProximity() Coordinate {
+	if f == nil {
+		return Coordinate{}
+	}
+	c, _ := f.Coordinate()
+	return c
+}
+
+// AsBBox returns the feature bounding box to restrict the next request, normalized. Returns false when the
+// feature has no bbox, or when it crosses the antimeridian since Mapbox rejects such a bbox, see BoundingBox.Split.
+func (f *Feature) AsBBox() (BoundingBox, bool) {
+	if f == nil {
+		return BoundingBox{}, false
+	}
+	box, ok := f.BoundingBox()
+	if !ok || box.validate() != nil {
+		return BoundingBox{}, false
+	}
+	return box.Normalize(), true
+}
+
+// WithDistancesFrom sets the Distance of every feature to its distance from c in meters.
+// Forward geocoding does this automatically when a Proximity is set.
+func (f Features) WithDistancesFrom(c Coordinate) Features {
+	for _, feature := range f {
+		if feature == nil {
+			continue
+		}
+		if coordinate, ok := feature.Coordinate(); ok {
+			feature.Distance = c.DistanceTo(coordinate)
+		}
+	}
+	return f
+}
+
+// SortByDistanceFrom stably reorders the features by distance from c, nearest first.
+// Features without a location go last, keeping their order.
+func (f Features) SortByDistanceFrom(c Coordinate) Features {
+	distance := func(feature *Feature) (float64, bool) {
+		if feature == nil {
+			return 0, false
+		}
+		coordinate, ok := feature.Coordinate()
+		if !ok {
+			return 0, false
+		}
+		return c.DistanceTo(coordinate), true
+	}
+
+	sort.SliceStable(f, func(i, j int) bool {
+		di, iOK := distance(f[i])
+		dj, jOK := distance(f[j])
+		if iOK != jOK {
+			return iOK
+		}
+		return di < dj
+	})
+	return f
+}
+
+// NearestTo returns the feature nearest to c and its distance in meters, e.g. the "Springfield" closest to the
+// user rather than the most relevant one. Features without a location are skipped, false when none has one.
+func (f Features) NearestTo(c Coordinate) (*Feature, float64, bool) {
+	var nearest *Feature
+	var distance float64
+	for _, feature := range f {
+		if feature == nil {
+			continue
+		}
+		coordinate, ok := feature.Coordinate()
+		if !ok {
+			continue
+		}
+		if d := c.DistanceTo(coordinate); nearest == nil || d < distance {
+			nearest, distance = feature, d
+		}
+	}
+	return nearest, distance, nearest != nil
+}
+
+// NearestTo returns the response feature nearest to c and its distance in meters, see Features.NearestTo
+func (r *ForwardGeocodeResponse) NearestTo(c Coordinate) (*Feature, float64, bool) {
+	return r.Features.NearestTo(c)
+}
+
+// WithDistancesFrom sets the Distance of every feature in the response to its distance from c in meters
+func (r *ForwardGeocodeResponse) WithDistancesFrom(c Coordinate) *ForwardGeocodeResponse {
+	r.Features.WithDistancesFrom(c)
+	return r
+}
+
+// WithDistancesFrom sets the Distance of every feature in the response to its distance from c in meters
+func (r *ReverseGeocodeResponse) WithDistancesFrom(c Coordinate) *ReverseGeocodeResponse {
+	r.Features.WithDistancesFrom(c)
+	return r
+}
+
+// Centroid returns the SphericalCentroid of the feature coordinates, e.g. to center a map on the results.
+// Features without a location are ignored, false when none has one.
+func (f Features) Centroid() (Coordinate, bool) {
+	var coords []Coordinate
+	for _, feature := range f {
+		if feature == nil {
+			continue
+		}
+		if coordinate, ok := feature.Coordinate(); ok {
+			coords = append(coords, coordinate)
+		}
+	}
+	if len(coords) == 0 {
+		return Coordinate{}, false
+	}
+	return SphericalCentroid(coords), true
+}
+
+// Centroid returns the centroid of the response features, see Features.Centroid
+func (r *ForwardGeocodeResponse) Centroid() (Coordinate, bool) {
+	return r.Features.Centroid()
+}
+
+// UniqueContextNames returns the distinct names of the level t of the features, see Feature.Component, in
+// first-seen order, e.g. the regions of the results for a faceted search dropdown
+func (f Features) UniqueContextNames(t Type) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, feature := range f {
+		if feature == nil {
+			continue
+		}
+		if name, ok := feature.Component(t); ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// UniqueContextNames returns the distinct names of the level t of the response features, see Features.UniqueContextNames
+func (r *ForwardGeocodeResponse) UniqueContextNames(t Type) []string {
+	return r.Features.UniqueContextNames(t)
+}
+
+// UniqueContextNames returns the distinct names of the level t of the response features, see Features.UniqueContextNames
+func (r *ReverseGeocodeResponse) UniqueContextNames(t Type) []string {
+	return r.Features.UniqueContextNames(t)
+}
+
+// GroupByCountry buckets the features by their upper case ISO 3166-1 alpha-2 country code, e.g. "US", keeping the
+// features in order within each group, for a dropdown grouped by country. countries lists the codes in order of
+// first appearance, features without a country are grouped under "".
+func (f Features) GroupByCountry() (groups map[string]Features, countries []string) {
+	groups = make(map[string]Features)
+	for _, feature := range f {
+		if feature == nil {
+			continue
+		}
+		code := feature.countryCode()
+		if _, ok := groups[code]; !ok {
+			countries = append(countries, code)
+		}
+		groups[code] = append(groups[code], feature)
+	}
+	return groups, countries
+}
+
+// GroupByCountry buckets the response features by country, see Features.GroupByCountry
+func (r *ForwardGeocodeResponse) GroupByCountry() (groups map[string]Features, countries []string) {
+	return r.Features.GroupByCountry()
+}
+
+// IsAmbiguous reports whether the relevance of the top two features differs by at most threshold, e.g. 0.05,
+// in which case the first result may not be the one meant and the user should pick. Fewer than two
+// features are never ambiguous.
+func (f Features) IsAmbiguous(threshold float64) bool {
+	if len(f) < 2 || f[0] == nil || f[1] == nil {
+		return false
+	}
+	return math.Abs(f[0].Relevance-f[1].Relevance) <= threshold
+}
+
+// IsAmbiguous reports whether the top two features of the response have a relevance within threshold, see Features.IsAmbiguous
+func (r *ForwardGeocodeResponse) IsAmbiguous(threshold float64) bool {
+	return r.Features.IsAmbiguous(threshold)
+}
+
+// SortByTypePriority stably reorders the features by the first of their types found in order, e.g.
+// Types{TypeAddress, TypePlace} puts addresses first. Features of unlisted types keep their order at the end.
+func (f Features) SortByTypePriority(order Types) Features {
+	priorities := make(map[Type]int, len(order))
+	for i, t := range order {
+		if _, ok := priorities[t]; !ok {
+			priorities[t] = i
+		}
+	}
+
+	priority := func(feature *Feature) int {
+		best := len(order)
+		if feature == nil {
+			return best
+		}
+		types := feature.PlaceType
+		if feature.Properties != nil && feature.Properties.FeatureType != "" {
+			types = append([]string{feature.Properties.FeatureType}, types...)
+		}
+		for _, t := range types {
+			if p, ok := priorities[Type(t)]; ok && p < best {
+				best = p
+			}
+		}
+		return best
+	}
+
+	sort.SliceStable(f, func(i, j int) bool {
+		return priority(f[i]) < priority(f[j])
+	})
+	return f
+}
+
+// RankByRelevanceAndDistance stably reorders the features by a score blending their relevance and their
+// proximity to c, best first. The score is (1-weight)*relevance + weight*proximity, where proximity is
+// 1 for the nearest feature down to 0 for the farthest, so weight 0 keeps the relevance order and weight 1
+// sorts by distance. weight is clamped to [0, 1]; features without a location have a proximity of 0.
+func (f Features) RankByRelevanceAndDistance(c Coordinate, weight float64) Features {
+	weight = math.Max(0, math.Min(1, weight))
+
+	distances := make(map[*Feature]float64, len(f))
+	nearest, farthest := math.Inf(1), 0.0
+	for _, feature := range f {
+		if feature == nil {
+			continue
+		}
+		if coordinate, ok := feature.Coordinate(); ok {
+			d := c.DistanceTo(coordinate)
+			distances[feature] = d
+			nearest, farthest = math.Min(nearest, d), math.Max(farthest, d)
+		}
+	}
+
+	score := func(feature *Feature) float64 {
+		if feature == nil {
+			return math.Inf(-1)
+		}
+		proximity := 0.0
+		if d, ok := distances[feature]; ok {
+			proximity = 1
+			if farthest > nearest {
+				proximity = (farthest - d) / (farthest - nearest)
+			}
+		}
+		return (1-weight)*feature.Relevance + weight*proximity
+	}
+
+	sort.SliceStable(f, func(i, j int) bool {
+		return score(f[i]) > score(f[j])
+	})
+	return f
+}
+
+// excluding returns the features none of whose types are in types, filtering in place
+func (f Features) excluding(types Types) Features {
+	if len(types) == 0 {
+		return f
+	}
+
+	excluded := func(feature *Feature) bool {
+		for _, t := range types {
+			if feature.Properties != nil && Type(feature.Properties.FeatureType) == t {
+				return true
+			}
+			for _, placeType := range feature.PlaceType {
+				if Type(placeType) == t {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	kept := f[:0]
+	for _, feature := range f {
+		if feature != nil && !excluded(feature) {
+			kept = append(kept, feature)
+		}
+	}
+	return kept
+}
+
+// Component returns the name of the feature component of type t.
+// The feature itself is used when it is of type t, otherwise its geocoding context is searched, then the Search Box
+// context of its properties. Without either context the component is parsed from the properties full_address.
+func (f *Feature) Component(t Type) (string, bool) {
+	for _, placeType := range f.PlaceType {
+		if Type(placeType) == t {
+			return f.Text, true
+		}
+	}
+	if f.Properties != nil && f.Properties.Name != "" && Type(f.Properties.FeatureType) == t {
+		return f.Properties.Name, true
+	}
+
+	for _, context := range f.Context {
+		if context != nil && context.Type() == t {
+			return context.Text, true
+		}
+	}
+
+	if f.Properties == nil {
+		return "", false
+	}
+	if f.Properties.Context != nil {
+		if component := f.Properties.Context.component(t); component != nil && component.Name != "" {
+			return component.Name, true
+		}
+		return "", false
+	}
+	if len(f.Context) == 0 {
+		return f.Properties.fullAddressComponent(t, f.countryCode())
+	}
+
+	return "", false
+}
+
+// fullAddressComponent returns the component of type t parsed from the full_address, see ParseFullAddress
+func (p *Properties) fullAddressComponent(t Type, countryCode string) (string, bool) {
+	address := ParseFullAddress(p.FullAddress, countryCode)
+	var name string
+	switch t {
+	case TypePlace:
+		name = address.City
+	case TypeRegion:
+		name = address.Region
+	case TypePostcode:
+		name = address.Postcode
+	case TypeCountry:
+		name = address.Country
+	}
+	return name, name != ""
+}
+
+// splitPostcode splits "California 92009" or "10115 Berlin" into the name and the postcode, the words
+// containing a digit at either end are taken as the postcode
+func splitPostcode(part string) (name, postcode string) {
+	words := strings.Fields(part)
+	hasDigit := func(word string) bool { return strings.ContainsAny(word, "0123456789") }
+
+	if len(words) > 1 && hasDigit(words[0]) {
+		return strings.Join(words[1:], " "), words[0]
+	}
+
+	i := len(words)
+	for i > 0 && hasDigit(words[i-1]) {
+		i--
+	}
+	return strings.Join(words[:i], " "), strings.Join(words[i:], " ")
+}
+
+// Postcode returns the postal code of the feature, if any
+func (f *Feature) Postcode() (string, bool) {
+	return f.Component(TypePostcode)
+}
+
+// City returns the city (place) of the feature, if any
+func (f *Feature) City() (string, bool) {
+	return f.Component(TypePlace)
+}
+
+// Region returns the region (state, province, ...) of the feature, if any
+func (f *Feature) Region() (string, bool) {
+	return f.Component(TypeRegion)
+}
+
+// Country returns the country of the feature, if any
+func (f *Feature) Country() (string, bool) {
+	return f.Component(TypeCountry)
+}
+
+// StreetAddress returns the house number and street of the feature, e.g. "12-14" and "Main St".
+// It uses the Search Box address context, then the geocoding address number and text, and finally
+// falls back to parsing the first line of the address. number is empty when none is found.
+func (f *Feature) StreetAddress() (number, street string) {
+	if f.Properties != nil && f.Properties.Context != nil && f.Properties.Context.Address != nil {
+		address := f.Properties.Context.Address
+		if address.AddressNumber != "" || address.StreetName != "" {
+			return address.AddressNumber, address.StreetName
+		}
+	}
+	if f.Address != "" {
+		return f.Address, f.Text
+	}
+
+	var line string
+	switch {
+	case f.Properties != nil && f.Properties.Address != "":
+		line = f.Properties.Address
+	case f.Properties != nil && f.Properties.FullAddress != "":
+		line = firstLine(f.Properties.FullAddress)
+	case f.Kind() == KindAddress || f.Kind() == KindStreet:
+		line = firstLine(f.PlaceName)
+	}
+	return parseStreetLine(line)
+}
+
+// Unit returns the secondary address of the feature, e.g. "Apt 4", from the feature itself when it is a
+// secondary address or from its Search Box context. Empty when the feature has none.
+func (f *Feature) Unit() string {
+	unit, _ := f.Component(TypeSecondaryAddress)
+	return unit
+}
+
+func firstLine(address string) string {
+	if i := strings.IndexByte(address, ','); i >= 0 {
+		return address[:i]
+	}
+	return address
+}
+
+// parseStreetLine splits "12-14 Main St" into its leading house number and the street,
+// the first word is taken as the number when it contains a digit
+func parseStreetLine(line string) (number, street string) {
+	line = strings.TrimSpace(line)
+	i := strings.IndexByte(line, ' ')
+	if i < 0 {
+		return "", line
+	}
+	if !strings.ContainsAny(line[:i], "0123456789") {
+		return "", line
+	}
+	return line[:i], strings.TrimSpace(line[i+1:])
+}
+
+// Equal reports whether both features describe the same result, comparing IDs, geometry and key properties
+func (f *Feature) Equal(other *Feature) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+
+	if f.ID != other.ID ||
+		f.Type != other.Type ||
+		f.Text != other.Text ||
+		f.PlaceName != other.PlaceName ||
+		f.Address != other.Address ||
+		!equalStrings(f.PlaceType, other.PlaceType) {
+		return false
+	}
+
+	var properties, otherProperties Properties
+	if f.Properties != nil {
+		properties = *f.Properties
+	}
+	if other.Properties != nil {
+		otherProperties = *other.Properties
+	}
+	if !properties.equal(&otherProperties) {
+		return false
+	}
+
+	var geometry, otherGeometry Geometry
+	if f.Geometry != nil {
+		geometry = *f.Geometry
+	}
+	if other.Geometry != nil {
+		otherGeometry = *other.Geometry
+	}
+	return geometry.Type == otherGeometry.Type &&
+		geometry.Interpolated == otherGeometry.Interpolated &&
+		equalFloats(geometry.Coordinates, otherGeometry.Coordinates) &&
+		equalPositions(geometry.Line, otherGeometry.Line) &&
+		equalRings(geometry.Polygon, otherGeometry.Polygon) &&
+		equalPolygons(geometry.MultiPolygon, otherGeometry.MultiPolygon)
+}
+
+func equalPositions(a, b [][]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalFloats(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalRings(a, b [][][]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalPositions(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalPolygons(a, b [][][][]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalRings(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares the properties identifying a result, leaving out the Search Box context and external IDs
+func (p *Properties) equal(other *Properties) bool {
+	return p.Accuracy == other.Accuracy &&
+		p.Address == other.Address &&
+		p.Category == other.Category &&
+		p.Maki == other.Maki &&
+		p.Landmark == other.Landmark &&
+		p.Wikidata == other.Wikidata &&
+		p.ShortCode == other.ShortCode &&
+		p.Name == other.Name &&
+		p.MapboxID == other.MapboxID &&
+		p.FeatureType == other.FeatureType &&
+		p.FullAddress == other.FullAddress
+}
+
+// CanonicalID returns the identifier to persist the feature with: the Search Box mapbox_id, which is stable across
+// sessions, or the geocoding id otherwise, which is positional and may change between responses
+func (f *Feature) CanonicalID() string {
+	if f == nil {
+		return ""
+	}
+	if f.Properties != nil && f.Properties.MapboxID != "" {
+		return f.Properties.MapboxID
+	}
+	return f.ID
+}
+
+// ValidateFeatureID checks id is a geocoding id, "{type}.{digits}" such as "place.11334486376224420", or a
+// mapbox_id, the base64url encoding of a "urn:mbx..." URN such as "dXJuOm1ieHBvaTo0ZTg2"
+func ValidateFeatureID(id string) error {
+	if i := strings.IndexByte(id, '.'); i > 0 {
+		if digits := id[i+1:]; digits != "" && strings.Trim(digits, "0123456789") == "" {
+			return nil
+		}
+		return fmt.Errorf("invalid feature id %q, expected {type}.{digits}", id)
+	}
+
+	urn, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(id, "="))
+	if err != nil || !strings.HasPrefix(string(urn), "urn:mbx") {
+		return fmt.Errorf("invalid feature id %q, expected a {type}.{digits} id or a mapbox_id", id)
+	}
+	return nil
+}
+
+// HashKey returns a key identifying the feature, suitable for use as a map key.
+// Features that are Equal share the same key.
+func (f *Feature) HashKey() string {
+	if f == nil {
+		return ""
+	}
+
+	// a nil geometry is keyed as an empty one, as Equal compares them
+	var geometry Geometry
+	if f.Geometry != nil {
+		geometry = *f.Geometry
+	}
+
+	var b strings.Builder
+	b.WriteString(f.ID)
+	if geometry.Type == "" && len(geometry.Coordinates) == 0 && len(geometry.Line) == 0 &&
+		len(geometry.Polygon) == 0 && len(geometry.MultiPolygon) == 0 {
+		return b.String()
+	}
+	b.WriteByte('|')
+	b.WriteString(geometry.Type)
+	b.WriteByte('|')
+	writePosition(&b, geometry.Coordinates)
+	b.WriteByte('|')
+	writePositions(&b, geometry.Line)
+	b.WriteByte('|')
+	writeRings(&b, geometry.Polygon)
+	b.WriteByte('|')
+	for i, polygon := range geometry.MultiPolygon {
+		if i > 0 {
+			b.WriteByte('!')
+		}
+		writeRings(&b, polygon)
+	}
+	return b.String()
+}
+
+// writePosition, writePositions and writeRings write the nested coordinates of a geometry with a separator per level
+func writePosition(b *strings.Builder, position []float64) {
+	for i, c := range position {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		// -0 equals 0
+		if c == 0 {
+			c = 0
+		}
+		b.WriteString(formatDegrees(c))
+	}
+}
+
+func writePositions(b *strings.Builder, positions [][]float64) {
+	for i, position := range positions {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		writePosition(b, position)
+	}
+}
+
+func writeRings(b *strings.Builder, rings [][][]float64) {
+	for i, ring := range rings {
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		writePositions(b, ring)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////
+
+type FeatureKind string
+
+const (
+	KindUnknown          = FeatureKind("")
+	KindAddress          = FeatureKind("address")
+	KindSecondaryAddress = FeatureKind("secondary_address")
+	KindStreet           = FeatureKind("street")
+	KindPOI              = FeatureKind("poi")
+	KindNeighborhood     = FeatureKind("neighborhood")
+	KindLocality         = FeatureKind("locality")
+	KindPlace            = FeatureKind("place")
+	KindDistrict         = FeatureKind("district")
+	KindPostcode         = FeatureKind("postcode")
+	KindRegion           = FeatureKind("region")
+	KindCountry          = FeatureKind("country")
+)
+
+// Kind classifies the feature from Properties.FeatureType (Search Box), falling back to its first place type (geocoding v5)
+func (f *Feature) Kind() FeatureKind {
+	featureType := ""
+	if f.Properties != nil {
+		featureType = f.Properties.FeatureType
+	}
+	if featureType == "" && len(f.PlaceType) > 0 {
+		featureType = f.PlaceType[0]
+	}
+
+	switch kind := FeatureKind(featureType); kind {
+	case KindAddress, KindSecondaryAddress, KindStreet, KindPOI, KindNeighborhood, KindLocality, KindPlace, KindDistrict, KindPostcode, KindRegion, KindCountry:
+		return kind
+	default:
+		return KindUnknown
+	}
+}
+
+// IsAddressable reports whether the feature is a precise location that can be navigated to: an address,
+// secondary address, street or POI
+func (f *Feature) IsAddressable() bool {
+	switch f.Kind() {
+	case KindAddress, KindSecondaryAddress, KindStreet, KindPOI:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsAdministrative reports whether the feature is an administrative or postal area, from neighborhood up to country
+func (f *Feature) IsAdministrative() bool {
+	switch f.Kind() {
+	case KindNeighborhood, KindLocality, KindPlace, KindDistrict, KindPostcode, KindRegion, KindCountry:
+		return true
+	default:
+		return false
+	}
+}
+
+// partialDecoder is a response with features that can be decoded one by one, see MapboxConfig.LenientDecoding
+type partialDecoder interface {
+	// decodePartial decodes the response keeping the features that decode, it returns a PartialDecodeError listing
+	// the others, nil when there are none, or the decoding error of the response itself when it is malformed too
+	decodePartial(body []byte) error
+}
+
+// decodeFeatures decodes the raw features, skipping and reporting the malformed ones
+func decodeFeatures(raw []json.RawMessage) (Features, error) {
+	features := make(Features, 0, len(raw))
+	var partialErr PartialDecodeError
+	for i, data := range raw {
+		var feature *Feature
+		if err := json.Unmarshal(data, &feature); err != nil {
+			partialErr.Indices = append(partialErr.Indices, i)
+			partialErr.Errs = append(partialErr.Errs, err)
+			continue
+		}
+		features = append(features, feature)
+	}
+	if len(partialErr.Indices) == 0 {
+		return features, nil
+	}
+	partialErr.Total = len(raw)
+	return features, partialErr
+}
+
+type forwardGeocodeResponse ForwardGeocodeResponse
+
+func (r *ForwardGeocodeResponse) decodePartial(body []byte) error {
+	raw := struct {
+		*forwardGeocodeResponse
+		Features []json.RawMessage `json:"features"`
+	}{forwardGeocodeResponse: (*forwardGeocodeResponse)(r)}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return err
+	}
+	features, err := decodeFeatures(raw.Features)
+	r.Features = features
+	return err
+}
+
+type reverseGeocodeResponse ReverseGeocodeResponse
+
+func (r *ReverseGeocodeResponse) decodePartial(body []byte) error {
+	raw := struct {
+		*reverseGeocodeResponse
+		Features []json.RawMessage `json:"features"`
+	}{reverseGeocodeResponse: (*reverseGeocodeResponse)(r)}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return err
+	}
+	features, err := decodeFeatures(raw.Features)
+	r.Features = features
+	return err
+}
+
+type searchBoxForwardResponse SearchBoxForwardResponse
+
+func (r *SearchBoxForwardResponse) decodePartial(body []byte) error {
+	raw := struct {
+		*searchBoxForwardResponse
+		Features []json.RawMessage `json:"features"`
+	}{searchBoxForwardResponse: (*searchBoxForwardResponse)(r)}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return err
+	}
+	features, err := decodeFeatures(raw.Features)
+	r.Features = features
+	return err
+}
@@ -0,0 +1,119 @@
+package mapbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// FeatureCollection is a GeoJSON FeatureCollection, e.g. to merge and export the features of several geocoding responses
+type FeatureCollection struct {
+	Type     string   `json:"type"`
+	Features Features `json:"features"`
+}
+
+// NewFeatureCollection returns a FeatureCollection of features, skipping nil features
+func NewFeatureCollection(features ...*Feature) *FeatureCollection {
+	collection := &FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make(Features, 0, len(features)),
+	}
+	for _, feature := range features {
+		if feature != nil {
+			collection.Features = append(collection.Features, feature)
+		}
+	}
+	return collection
+}
+
+// MarshalJSON always encodes a valid FeatureCollection: the type is set, nil features are skipped,
+// an empty collection has an empty features array and every feature has the "Feature" type.
+func (fc FeatureCollection) MarshalJSON() ([]byte, error) {
+	features := make([]Feature, 0, len(fc.Features))
+	for _, feature := range fc.Features {
+		if feature == nil {
+			continue
+		}
+		f := *feature
+		if f.Type == "" {
+			f.Type = "Feature"
+		}
+		features = append(features, f)
+	}
+
+	return json.Marshal(struct {
+		Type     string    `json:"type"`
+		Features []Feature `json:"features"`
+	}{"FeatureCollection", features})
+}
+
+// WriteGeoJSONL writes the features as newline-delimited GeoJSON, one complete Feature per line, e.g. to stream
+// them into tippecanoe or BigQuery. Nil features are skipped and every feature has the "Feature" type.
+func WriteGeoJSONL(w io.Writer, features Features) error {
+	encoder := json.NewEncoder(w)
+	for i, feature := range features {
+		if feature == nil {
+			continue
+		}
+		f := *feature
+		if f.Type == "" {
+			f.Type = "Feature"
+		}
+		if err := encoder.Encode(f); err != nil {
+			return fmt.Errorf("failed to write feature %v. %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateGeocodeResponseJSON checks a geocoding or Search Box response body has the shape this package decodes:
+// a FeatureCollection type and features each with an id (or a Search Box mapbox_id) and a geometry with a type
+// and coordinates. It is meant for contract tests against the live API, to catch breaking response changes early.
+func ValidateGeocodeResponseJSON(data []byte) error {
+	var response struct {
+		Type     *string           `json:"type"`
+		Features []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("failed to decode response. %w", err)
+	}
+	if response.Type == nil {
+		return fmt.Errorf("missing response type")
+	}
+	if *response.Type != featureCollection {
+		return fmt.Errorf("unexpected response type %q, expected %q", *response.Type, featureCollection)
+	}
+	if response.Features == nil {
+		return fmt.Errorf("missing features")
+	}
+
+	for i, data := range response.Features {
+		var feature struct {
+			ID         *string `json:"id"`
+			Properties *struct {
+				MapboxID string `json:"mapbox_id"`
+			} `json:"properties"`
+			Geometry *struct {
+				Type        *string         `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		}
+		if err := json.Unmarshal(data, &feature); err != nil {
+			return fmt.Errorf("failed to decode feature %v. %w", i, err)
+		}
+		if (feature.ID == nil || *feature.ID == "") && (feature.Properties == nil || feature.Properties.MapboxID == "") {
+			return fmt.Errorf("feature %v has no id", i)
+		}
+		if feature.Geometry == nil {
+			return fmt.Errorf("feature %v has no geometry", i)
+		}
+		if feature.Geometry.Type == nil || *feature.Geometry.Type == "" {
+			return fmt.Errorf("feature %v geometry has no type", i)
+		}
+		var coordinates []json.RawMessage
+		if err := json.Unmarshal(feature.Geometry.Coordinates, &coordinates); err != nil || len(coordinates) == 0 {
+			return fmt.Errorf("feature %v geometry has no coordinates array", i)
+		}
+	}
+	return nil
+}
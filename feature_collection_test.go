@@ -0,0 +1,93 @@
+package mapbox
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFeatureCollectionJSON(t *testing.T) {
+	first := &Feature{ID: "place.1", Type: "Feature", Text: "Carlsbad", Center: []float64{-117.3, 33.1}}
+	second := &Feature{ID: "poi.2", Text: "Coffee"}
+
+	data, err := json.Marshal(NewFeatureCollection(first, nil, second))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var decoded ForwardGeocodeResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decoded.Type != "FeatureCollection" || len(decoded.Features) != 2 {
+		t.Fatalf("unexpected collection %s", data)
+	}
+	if decoded.Features[0].ID != "place.1" || decoded.Features[1].Type != "Feature" {
+		t.Errorf("unexpected features %s", data)
+	}
+
+	data, err = json.Marshal(FeatureCollection{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if expected := `{"type":"FeatureCollection","features":[]}`; string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestValidateGeocodeResponseJSON(t *testing.T) {
+	for _, fixture := range []string{
+		`{"type":"FeatureCollection","features":[` + addressFeatureJSON + `]}`,
+		searchBoxForwardJSON,
+		`{"type":"FeatureCollection","features":[]}`,
+	} {
+		if err := ValidateGeocodeResponseJSON([]byte(fixture)); err != nil {
+			t.Errorf("expected a valid fixture, got %v", err)
+		}
+	}
+
+	for _, body := range []string{
+		`not json`,
+		`{"features":[]}`,
+		`{"type":"Feature","features":[]}`,
+		`{"type":"FeatureCollection"}`,
+		`{"type":"FeatureCollection","features":[{"geometry":{"type":"Point","coordinates":[0,0]}}]}`,
+		`{"type":"FeatureCollection","features":[{"id":5,"geometry":{"type":"Point","coordinates":[0,0]}}]}`,
+		`{"type":"FeatureCollection","features":[{"id":"place.1"}]}`,
+		`{"type":"FeatureCollection","features":[{"id":"place.1","geometry":{"coordinates":[0,0]}}]}`,
+		`{"type":"FeatureCollection","features":[{"id":"place.1","geometry":{"type":"Point","coordinates":"0,0"}}]}`,
+	} {
+		if err := ValidateGeocodeResponseJSON([]byte(body)); err == nil {
+			t.Errorf("expected %s to be rejected", body)
+		}
+	}
+}
+
+func TestWriteGeoJSONL(t *testing.T) {
+	var b strings.Builder
+	features := Features{
+		decodeFeature(t, addressFeatureJSON),
+		nil,
+		{ID: "poi.2", Text: "Coffee", Geometry: &Geometry{Type: "Point", Coordinates: []float64{-117.3, 33.1}}},
+	}
+	if err := WriteGeoJSONL(&b, features); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %v", len(lines))
+	}
+	for i, line := range lines {
+		var feature Feature
+		if err := json.Unmarshal([]byte(line), &feature); err != nil {
+			t.Fatalf("line %v: expected a feature, got %v", i, err)
+		}
+		if feature.Type != "Feature" || feature.ID != features[2*i].ID {
+			t.Errorf("line %v: unexpected feature %+v", i, feature)
+		}
+	}
+	if !strings.Contains(lines[1], `"coordinates":[-117.3,33.1]`) {
+		t.Errorf("expected [lng,lat] coordinates, got %s", lines[1])
+	}
+}
@@ -0,0 +1,553 @@
+package mapbox
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+const addressFeatureJSON = `{
+	"id": "address.4356035406756260",
+	"type": "Feature",
+	"place_type": ["address"],
+	"relevance": 1,
+	"properties": {"accuracy": "rooftop"},
+	"text": "Hidden Valley Road",
+	"place_name": "6005 Hidden Valley Road, Carlsbad, California 92011, United States",
+	"center": [-117.31, 33.1226],
+	"geometry": {"type": "Point", "coordinates": [-117.31, 33.1226]},
+	"address": "6005",
+	"context": [
+		{"id": "neighborhood.2104653", "text": "Palomar Airport"},
+		{"id": "postcode.8453667903266430", "text": "92011"},
+		{"id": "place.11334486376224420", "wikidata": "Q491114", "text": "Carlsbad"},
+		{"id": "region.9803118085738010", "short_code": "US-CA", "wikidata": "Q99", "text": "California"},
+		{"id": "country.9053006287256050", "short_code": "us", "wikidata": "Q30", "text": "United States"}
+	]
+}`
+
+func decodeFeature(t *testing.T, data string) *Feature {
+	t.Helper()
+	var feature Feature
+	if err := json.Unmarshal([]byte(data), &feature); err != nil {
+		t.Fatalf("failed to decode feature: %v", err)
+	}
+	return &feature
+}
+
+func TestFeatureComponents(t *testing.T) {
+	feature := decodeFeature(t, addressFeatureJSON)
+
+	tests := []struct {
+		name     string
+		get      func() (string, bool)
+		expected string
+	}{
+		{"postcode", feature.Postcode, "92011"},
+		{"city", feature.City, "Carlsbad"},
+		{"region", feature.Region, "California"},
+		{"country", feature.Country, "United States"},
+	}
+	for _, test := range tests {
+		actual, ok := test.get()
+		if !ok || actual != test.expected {
+			t.Errorf("%v: expected %q, got %q (ok: %v)", test.name, test.expected, actual, ok)
+		}
+	}
+
+	if street, ok := feature.Component(TypeAddress); !ok || street != "Hidden Valley Road" {
+		t.Errorf("expected feature itself to match address type, got %q", street)
+	}
+	if _, ok := feature.Component(TypeDistrict); ok {
+		t.Errorf("expected no district component")
+	}
+
+	searchBox := &Feature{Properties: &Properties{
+		Name:        "Starbucks",
+		FeatureType: "poi",
+		Context: &SearchBoxContext{
+			Place:   &SearchBoxContextComponent{Name: "Carlsbad"},
+			Country: &SearchBoxContextComponent{Name: "United States"},
+		},
+	}}
+	if city, ok := searchBox.City(); !ok || city != "Carlsbad" {
+		t.Errorf("expected the search box context place, got %q", city)
+	}
+	if poi, ok := searchBox.Component(TypePOI); !ok || poi != "Starbucks" {
+		t.Errorf("expected the feature itself to match poi type, got %q", poi)
+	}
+	if _, ok := searchBox.Region(); ok {
+		t.Errorf("expected no region component")
+	}
+
+	fullAddress := &Feature{Properties: &Properties{FullAddress: "6965 El Camino Real, Carlsbad, California 92009, United States"}}
+	for _, test := range []struct {
+		t        Type
+		expected string
+	}{
+		{TypePlace, "Carlsbad"},
+		{TypeRegion, "California"},
+		{TypePostcode, "92009"},
+		{TypeCountry, "United States"},
+	} {
+		if actual, ok := fullAddress.Component(test.t); !ok || actual != test.expected {
+			t.Errorf("%v: expected %q from the full address, got %q", test.t, test.expected, actual)
+		}
+	}
+
+	postcodeFirst := &Feature{Properties: &Properties{FullAddress: "Hauptstraße 5, 10115 Berlin, Germany"}}
+	if city, ok := postcodeFirst.City(); !ok || city != "Berlin" {
+		t.Errorf("expected Berlin from the full address, got %q", city)
+	}
+	if postcode, ok := postcodeFirst.Postcode(); !ok || postcode != "10115" {
+		t.Errorf("expected 10115 from the full address, got %q", postcode)
+	}
+	if region, ok := postcodeFirst.Region(); ok {
+		t.Errorf("expected no region, got %q", region)
+	}
+}
+
+func TestFeatureEqual(t *testing.T) {
+	feature := decodeFeature(t, addressFeatureJSON)
+	same := decodeFeature(t, addressFeatureJSON)
+
+	if !feature.Equal(same) || feature.HashKey() != same.HashKey() {
+		t.Errorf("expected identical features to be equal")
+	}
+
+	moved := decodeFeature(t, addressFeatureJSON)
+	moved.Geometry.Coordinates = []float64{-117.3, 33.1226}
+	if feature.Equal(moved) || feature.HashKey() == moved.HashKey() {
+		t.Errorf("expected features with different geometry to differ")
+	}
+
+	withoutProperties := decodeFeature(t, addressFeatureJSON)
+	withoutProperties.Properties = nil
+	if feature.Equal(withoutProperties) {
+		t.Errorf("expected features with different properties to differ")
+	}
+	emptyProperties := decodeFeature(t, addressFeatureJSON)
+	emptyProperties.Properties = &Properties{}
+	if !withoutProperties.Equal(emptyProperties) {
+		t.Errorf("expected nil and empty properties to be equal")
+	}
+	recategorized := decodeFeature(t, addressFeatureJSON)
+	recategorized.Properties.Accuracy = "interpolated"
+	if feature.Equal(recategorized) {
+		t.Errorf("expected features with different accuracy to differ")
+	}
+	withContext := decodeFeature(t, addressFeatureJSON)
+	withContext.Properties.Context = &SearchBoxContext{Place: &SearchBoxContextComponent{Name: "Carlsbad"}}
+	if !feature.Equal(withContext) {
+		t.Errorf("expected the search box context to be ignored")
+	}
+
+	withoutGeometry := decodeFeature(t, addressFeatureJSON)
+	withoutGeometry.Geometry = nil
+	emptyGeometry := decodeFeature(t, addressFeatureJSON)
+	emptyGeometry.Geometry = &Geometry{}
+	if !withoutGeometry.Equal(emptyGeometry) || withoutGeometry.HashKey() != emptyGeometry.HashKey() {
+		t.Errorf("expected nil and empty geometries to be equal with the same key")
+	}
+
+	var nilFeature *Feature
+	if nilFeature.Equal(feature) || feature.Equal(nil) || !nilFeature.Equal(nil) {
+		t.Errorf("unexpected nil feature equality")
+	}
+}
+
+func TestFeatureRawProperties(t *testing.T) {
+	feature := decodeFeature(t, `{"id":"poi.1","properties":{"accuracy":"rooftop","foursquare":"4b0586","tel":"+1 760"}}`)
+
+	if feature.Properties == nil || feature.Properties.Accuracy != "rooftop" {
+		t.Errorf("expected typed properties, got %+v", feature.Properties)
+	}
+
+	var extra struct {
+		Foursquare string `json:"foursquare"`
+		Tel        string `json:"tel"`
+	}
+	if err := json.Unmarshal(feature.RawProperties, &extra); err != nil {
+		t.Fatalf("expected raw properties, got %v", err)
+	}
+	if extra.Foursquare != "4b0586" || extra.Tel != "+1 760" {
+		t.Errorf("unexpected raw properties %s", feature.RawProperties)
+	}
+
+	if feature := decodeFeature(t, `{"id":"poi.1","properties":null}`); feature.RawProperties != nil {
+		t.Errorf("expected no raw properties, got %s", feature.RawProperties)
+	}
+}
+
+func TestFeatureKind(t *testing.T) {
+	tests := []struct {
+		feature        *Feature
+		kind           FeatureKind
+		addressable    bool
+		administrative bool
+	}{
+		{decodeFeature(t, addressFeatureJSON), KindAddress, true, false},
+		{&Feature{PlaceType: []string{"poi", "poi.landmark"}}, KindPOI, true, false},
+		{&Feature{PlaceType: []string{"region"}}, KindRegion, false, true},
+		{&Feature{Properties: &Properties{FeatureType: "postcode"}, PlaceType: []string{"poi"}}, KindPostcode, false, true},
+		{&Feature{Properties: &Properties{FeatureType: "category"}}, KindUnknown, false, false},
+		{&Feature{}, KindUnknown, false, false},
+	}
+
+	for _, test := range tests {
+		if kind := test.feature.Kind(); kind != test.kind {
+			t.Errorf("expected kind %q, got %q", test.kind, kind)
+		}
+		if addressable := test.feature.IsAddressable(); addressable != test.addressable {
+			t.Errorf("expected addressable %v for %q", test.addressable, test.kind)
+		}
+		if administrative := test.feature.IsAdministrative(); administrative != test.administrative {
+			t.Errorf("expected administrative %v for %q", test.administrative, test.kind)
+		}
+	}
+}
+
+func TestFeatureStreetAddress(t *testing.T) {
+	tests := []struct {
+		feature *Feature
+		number  string
+		street  string
+	}{
+		{decodeFeature(t, addressFeatureJSON), "6005", "Hidden Valley Road"},
+		{&Feature{Properties: &Properties{Context: &SearchBoxContext{Address: &SearchBoxContextComponent{AddressNumber: "12-14", StreetName: "Main St"}}}}, "12-14", "Main St"},
+		{&Feature{PlaceType: []string{"poi"}, Properties: &Properties{Address: "6965 El Camino Real"}}, "6965", "El Camino Real"},
+		{&Feature{Properties: &Properties{FullAddress: "221B Baker Street, London NW1 6XE, United Kingdom"}}, "221B", "Baker Street"},
+		{&Feature{PlaceType: []string{"address"}, PlaceName: "Main Street, Springfield"}, "", "Main Street"},
+		{&Feature{PlaceType: []string{"place"}, PlaceName: "Carlsbad, California"}, "", ""},
+	}
+
+	for _, test := range tests {
+		number, street := test.feature.StreetAddress()
+		if number != test.number || street != test.street {
+			t.Errorf("expected %q %q, got %q %q", test.number, test.street, number, street)
+		}
+	}
+}
+
+func TestFeaturesSortByTypePriority(t *testing.T) {
+	features := Features{
+		{ID: "place.1", PlaceType: []string{"place"}},
+		{ID: "poi.1", PlaceType: []string{"poi"}},
+		{ID: "address.1", PlaceType: []string{"address"}},
+		{ID: "region.1", PlaceType: []string{"region"}},
+		{ID: "address.2", Properties: &Properties{FeatureType: "address"}},
+		{ID: "postcode.1", PlaceType: []string{"postcode", "place"}},
+	}
+
+	features.SortByTypePriority(Types{TypeAddress, TypePlace})
+
+	expected := []string{"address.1", "address.2", "place.1", "postcode.1", "poi.1", "region.1"}
+	for i, id := range expected {
+		if features[i].ID != id {
+			t.Fatalf("expected order %v, got %v at %v", expected, features[i].ID, i)
+		}
+	}
+}
+
+func TestFeaturesIsAmbiguous(t *testing.T) {
+	tests := []struct {
+		relevances []float64
+		expected   bool
+	}{
+		{nil, false},
+		{[]float64{1}, false},
+		{[]float64{0.99, 0.97, 0.5}, true},
+		{[]float64{0.99, 0.8}, false},
+	}
+
+	for _, test := range tests {
+		var response ForwardGeocodeResponse
+		for _, relevance := range test.relevances {
+			response.Features = append(response.Features, &Feature{Relevance: relevance})
+		}
+		if actual := response.IsAmbiguous(0.05); actual != test.expected {
+			t.Errorf("%v: expected %v, got %v", test.relevances, test.expected, actual)
+		}
+	}
+}
+
+func TestFeatureUnit(t *testing.T) {
+	feature := &Feature{Properties: &Properties{
+		FeatureType: "address",
+		Context: &SearchBoxContext{
+			Address:          &SearchBoxContextComponent{AddressNumber: "12", StreetName: "Main St"},
+			SecondaryAddress: &SearchBoxContextComponent{Name: "Apt 4"},
+		},
+	}}
+	if unit := feature.Unit(); unit != "Apt 4" {
+		t.Errorf("expected the context unit, got %q", unit)
+	}
+	if address, ok := feature.ToAddress(); !ok || address.Unit != "Apt 4" || address.Number != "12" {
+		t.Errorf("unexpected address %+v", address)
+	}
+
+	secondary := &Feature{Properties: &Properties{FeatureType: "secondary_address", Name: "Unit 5"}}
+	if unit := secondary.Unit(); unit != "Unit 5" || !secondary.IsAddressable() {
+		t.Errorf("expected the secondary address itself, got %q", unit)
+	}
+	if unit := decodeFeature(t, addressFeatureJSON).Unit(); unit != "" {
+		t.Errorf("expected no unit, got %q", unit)
+	}
+}
+
+func TestFeatureBoundingBox(t *testing.T) {
+	feature := &Feature{Bbox: []float64{-117.35, 33.08, -117.24, 33.18}}
+	box, ok := feature.BoundingBox()
+	if !ok || box.Min != (Coordinate{Lat: 33.08, Lng: -117.35}) || box.Max != (Coordinate{Lat: 33.18, Lng: -117.24}) {
+		t.Errorf("unexpected bounding box %+v", box)
+	}
+
+	for _, bbox := range [][]float64{nil, {1, 2, 3}, {0, 10, 1, 5}} {
+		if _, ok := (&Feature{Bbox: bbox}).BoundingBox(); ok {
+			t.Errorf("expected %v to be rejected", bbox)
+		}
+	}
+}
+
+func TestFeatureAsProximityAndBBox(t *testing.T) {
+	city := &Feature{
+		ID:     "place.1",
+		Center: []float64{-117.3, 33.13},
+		Bbox:   []float64{-117.35, 33.08, -117.24, 33.18},
+	}
+	if proximity := city.AsProximity(); proximity != (Coordinate{Lat: 33.13, Lng: -117.3}) {
+		t.Errorf("unexpected proximity %v", proximity)
+	}
+	box, ok := city.AsBBox()
+	if !ok || box.Min != (Coordinate{Lat: 33.08, Lng: -117.35}) {
+		t.Errorf("unexpected bbox %+v", box)
+	}
+
+	client, _ := NewClient(&MapboxConfig{APIKey: "test"})
+	u, err := client.ForwardGeocodeURL(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "coffee", Proximity: city.AsProximity(), BBox: box})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if u.Query().Get("proximity") != "-117.3,33.13" || u.Query().Get("bbox") != "-117.35,33.08,-117.24,33.18" {
+		t.Errorf("unexpected query %v", u.Query())
+	}
+
+	fiji := &Feature{Bbox: []float64{177, -20, -178, -12}}
+	if _, ok := fiji.AsBBox(); ok {
+		t.Errorf("expected an antimeridian bbox to be rejected")
+	}
+	if proximity := (&Feature{}).AsProximity(); proximity != (Coordinate{}) {
+		t.Errorf("expected no proximity, got %v", proximity)
+	}
+}
+
+func TestFeaturesSortByDistanceFrom(t *testing.T) {
+	point := func(id string, lat, lng float64) *Feature {
+		return &Feature{ID: id, Geometry: &Geometry{Type: "Point", Coordinates: []float64{lng, lat}}}
+	}
+	features := Features{
+		point("far", 34, -118),
+		{ID: "nowhere"},
+		point("near", 33.13, -117.3),
+		point("middle", 33.5, -117.3),
+	}
+
+	features.SortByDistanceFrom(Coordinate{Lat: 33.12, Lng: -117.3})
+	var ids []string
+	for _, feature := range features {
+		ids = append(ids, feature.ID)
+	}
+	if expected := []string{"near", "middle", "far", "nowhere"}; !equalStrings(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestForwardGeocodeResponseNearestTo(t *testing.T) {
+	point := func(id string, lat, lng float64) *Feature {
+		return &Feature{ID: id, Geometry: &Geometry{Type: "Point", Coordinates: []float64{lng, lat}}}
+	}
+	reference := Coordinate{Lat: 39.8, Lng: -89.65}
+	response := &ForwardGeocodeResponse{Features: Features{
+		point("springfield-ma", 42.1, -72.59),
+		{ID: "nowhere"},
+		nil,
+		point("springfield-il", 39.8, -89.64),
+		point("springfield-mo", 37.21, -93.29),
+	}}
+
+	nearest, distance, ok := response.NearestTo(reference)
+	if !ok || nearest.ID != "springfield-il" {
+		t.Fatalf("expected springfield-il, got %+v, %v", nearest, ok)
+	}
+	if expected := reference.DistanceTo(Coordinate{Lat: 39.8, Lng: -89.64}); distance != expected {
+		t.Errorf("expected distance %v, got %v", expected, distance)
+	}
+
+	if _, _, ok := (&ForwardGeocodeResponse{Features: Features{{ID: "nowhere"}}}).NearestTo(reference); ok {
+		t.Errorf("expected no nearest feature without locations")
+	}
+}
+
+func TestFeaturesRankByRelevanceAndDistance(t *testing.T) {
+	point := func(id string, relevance, lat float64) *Feature {
+		return &Feature{ID: id, Relevance: relevance, Geometry: &Geometry{Type: "Point", Coordinates: []float64{-117.3, lat}}}
+	}
+	origin := Coordinate{Lat: 33.12, Lng: -117.3}
+
+	tests := []struct {
+		weight   float64
+		expected []string
+	}{
+		{0, []string{"relevant", "middle", "near", "nowhere"}},
+		{0.2, []string{"middle", "relevant", "near", "nowhere"}},
+		{0.5, []string{"near", "middle", "relevant", "nowhere"}},
+		{1, []string{"near", "middle", "relevant", "nowhere"}},
+		{2, []string{"near", "middle", "relevant", "nowhere"}},
+	}
+
+	for _, test := range tests {
+		features := Features{
+			point("relevant", 1, 34),
+			{ID: "nowhere", Relevance: 0.5},
+			point("near", 0.6, 33.13),
+			point("middle", 0.9, 33.5),
+		}
+		features.RankByRelevanceAndDistance(origin, test.weight)
+
+		var ids []string
+		for _, feature := range features {
+			ids = append(ids, feature.ID)
+		}
+		if !equalStrings(ids, test.expected) {
+			t.Errorf("weight %v: expected %v, got %v", test.weight, test.expected, ids)
+		}
+	}
+}
+
+func TestFeatureCanonicalID(t *testing.T) {
+	geocoded := decodeFeature(t, addressFeatureJSON)
+	if id := geocoded.CanonicalID(); id != "address.4356035406756260" {
+		t.Errorf("expected the geocoding id, got %q", id)
+	}
+	searchBox := &Feature{ID: "poi.1", Properties: &Properties{MapboxID: "dXJuOm1ieHBvaTo0ZTg2"}}
+	if id := searchBox.CanonicalID(); id != "dXJuOm1ieHBvaTo0ZTg2" {
+		t.Errorf("expected the mapbox_id, got %q", id)
+	}
+
+	for _, id := range []string{"address.4356035406756260", "place.1", "dXJuOm1ieHBvaTo0ZTg2"} {
+		if err := ValidateFeatureID(id); err != nil {
+			t.Errorf("%q: expected no error, got %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "place.", ".123", "place.12a", "Carlsbad", "aGVsbG8"} {
+		if err := ValidateFeatureID(id); err == nil {
+			t.Errorf("%q: expected an error", id)
+		}
+	}
+}
+
+func TestFeatureRoutablePoint(t *testing.T) {
+	searchBox := decodeFeature(t, `{"type":"Feature","geometry":{"type":"Point","coordinates":[-117.3101,33.1227]},
+		"properties":{"coordinates":{"latitude":33.1227,"longitude":-117.3101,"accuracy":"rooftop",
+			"routable_points":[{"name":"side","latitude":33.1225,"longitude":-117.3105},{"name":"default","latitude":33.1224,"longitude":-117.3100}]}}}`)
+	geocoded := decodeFeature(t, `{"id":"address.1","center":[-117.31,33.1226],"routable_points":{"points":[{"coordinates":[-117.3098,33.1223]}]}}`)
+
+	tests := []struct {
+		feature  *Feature
+		expected Coordinate
+		ok       bool
+	}{
+		{searchBox, Coordinate{Lat: 33.1224, Lng: -117.31}, true},
+		{geocoded, Coordinate{Lat: 33.1223, Lng: -117.3098}, true},
+		{decodeFeature(t, addressFeatureJSON), Coordinate{Lat: 33.1226, Lng: -117.31}, true},
+		{&Feature{}, Coordinate{}, false},
+	}
+	for _, test := range tests {
+		if point, ok := test.feature.RoutablePoint(); point != test.expected || ok != test.ok {
+			t.Errorf("%v: expected %v %v, got %v %v", test.feature.ID, test.expected, test.ok, point, ok)
+		}
+	}
+
+	if points := searchBox.Properties.Coordinates.RoutablePoints; len(points) != 2 || points[0].Name != "side" || searchBox.Properties.Coordinates.Accuracy != "rooftop" {
+		t.Errorf("unexpected routable points %+v", points)
+	}
+	data, err := json.Marshal(searchBox.Properties.Coordinates.RoutablePoints[0])
+	if err != nil || string(data) != `{"name":"side","latitude":33.1225,"longitude":-117.3105}` {
+		t.Errorf("unexpected routable point JSON %s, %v", data, err)
+	}
+}
+
+func TestFeaturesUniqueContextNames(t *testing.T) {
+	var searchBox SearchBoxForwardResponse
+	if err := json.Unmarshal([]byte(searchBoxForwardJSON), &searchBox); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	response := &ForwardGeocodeResponse{Features: Features{
+		decodeFeature(t, addressFeatureJSON),
+		decodeFeature(t, `{"id":"place.2","place_type":["place"],"text":"Austin","context":[{"id":"region.2","text":"Texas"}]}`),
+		nil,
+		searchBox.Features[0],
+		decodeFeature(t, `{"id":"country.1","place_type":["country"],"text":"Mexico"}`),
+	}}
+
+	if regions := response.UniqueContextNames(TypeRegion); !equalStrings(regions, []string{"California", "Texas"}) {
+		t.Errorf("unexpected regions %v", regions)
+	}
+	if places := response.UniqueContextNames(TypePlace); !equalStrings(places, []string{"Carlsbad", "Austin"}) {
+		t.Errorf("unexpected places %v", places)
+	}
+	if countries := response.UniqueContextNames(TypeCountry); !equalStrings(countries, []string{"United States", "Mexico"}) {
+		t.Errorf("unexpected countries %v", countries)
+	}
+}
+
+func TestFeaturesGroupByCountry(t *testing.T) {
+	var searchBox SearchBoxForwardResponse
+	if err := json.Unmarshal([]byte(searchBoxForwardJSON), &searchBox); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	carlsbad := decodeFeature(t, addressFeatureJSON)
+	austin := decodeFeature(t, `{"id":"place.2","place_type":["place"],"text":"Austin","context":[{"id":"region.2","text":"Texas"}]}`)
+	mexico := decodeFeature(t, `{"id":"country.1","place_type":["country"],"text":"Mexico","properties":{"short_code":"mx"}}`)
+	response := &ForwardGeocodeResponse{Features: Features{carlsbad, austin, mexico, nil, searchBox.Features[0]}}
+
+	groups, countries := response.GroupByCountry()
+	if !equalStrings(countries, []string{"US", "", "MX"}) {
+		t.Errorf("unexpected countries %v", countries)
+	}
+	if us := groups["US"]; len(us) != 2 || us[0] != carlsbad || us[1] != searchBox.Features[0] {
+		t.Errorf("unexpected US features %v", us)
+	}
+	if unknown := groups[""]; len(unknown) != 1 || unknown[0] != austin {
+		t.Errorf("unexpected features without a country %v", unknown)
+	}
+	if mx := groups["MX"]; len(mx) != 1 || mx[0] != mexico {
+		t.Errorf("unexpected MX features %v", mx)
+	}
+}
+
+func TestFeatureEqualShapes(t *testing.T) {
+	shapes := []string{
+		`{"type":"LineString","coordinates":[[-117.3,33.1],[-117.2,33.2]]}`,
+		`{"type":"LineString","coordinates":[[-117.3,33.1],[-117.2,33.3]]}`,
+		`{"type":"LineString","coordinates":[[-117.3,33.1,-117.2],[33.2]]}`,
+		`{"type":"Polygon","coordinates":[[[-117.3,33.1],[-117.2,33.1],[-117.2,33.2],[-117.3,33.1]]]}`,
+		`{"type":"Polygon","coordinates":[[[-117.3,33.1],[-117.2,33.1]],[[-117.2,33.2],[-117.3,33.1]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[-117.3,33.1],[-117.2,33.1],[-117.2,33.2],[-117.3,33.1]]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[-117.3,33.1],[-117.2,33.1]]],[[[-117.2,33.2],[-117.3,33.1]]]]}`,
+	}
+
+	for i, shape := range shapes {
+		feature := decodeFeature(t, `{"id":"place.1","geometry":`+shape+`}`)
+		same := decodeFeature(t, `{"id":"place.1","geometry":`+shape+`}`)
+		if !feature.Equal(same) || feature.HashKey() != same.HashKey() {
+			t.Errorf("%v: expected identical shapes to be equal with the same key", shape)
+		}
+		for _, other := range shapes[i+1:] {
+			different := decodeFeature(t, `{"id":"place.1","geometry":`+other+`}`)
+			if feature.Equal(different) || feature.HashKey() == different.HashKey() {
+				t.Errorf("expected %v and %v to differ", shape, other)
+			}
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package mapbox
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -21,17 +27,36 @@ type ReverseGeocodeRequest struct {
 	// optional
 	Country     string
 	Language    string
-	Limit       int
+	Limit       int // 1-5, a limit above 1 requires exactly one type in Types
 	ReverseMode ReverseMode
 	Routing     bool
-	Types       Types
+	// Types filters results by feature type. When several types are given Mapbox returns the most
+	// granular match (e.g. the address rather than its neighborhood), set a single type to force a level.
+	Types Types
+	// Zoom is the map zoom (0-22) of a click-to-identify request, it sets the types to TypesForZoom when Types is empty
+	Zoom *int
+	// CoordinatePrecision overrides the client CoordinatePrecision with 1-15 decimals, -1 sends full precision
+	CoordinatePrecision int
+	// ExcludeTypes drops the features of any of these types from the response. The filtering happens
+	// client-side after the request, excluded features are still billed.
+	ExcludeTypes Types
+
+	// PreferAddressable first requests the nearest address, falling back to the request as given when there is
+	// none within AddressableRadius meters (0 accepts any distance), e.g. for a pin dropped in a park, or when the
+	// address request fails. Addresses without a location are ignored.
+	// Note that the fallback is billed as a separate request.
+	PreferAddressable bool
+	AddressableRadius float64
 }
 
 type ReverseGeocodeResponse struct {
-	Type        string     `json:"type"`
-	Query       []float64  `json:"query"`
-	Features    []*Feature `json:"features"`
-	Attribution string     `json:"attribution"`
+	Type        string    `json:"type"`
+	Query       []float64 `json:"query"`
+	Features    Features  `json:"features"`
+	Attribution string    `json:"attribution"`
+
+	// AddressableMatch is set when the results come from the PreferAddressable address request
+	AddressableMatch bool `json:"-"`
 }
 
 //////////////////////////////////////////////////////////////////
@@ -51,17 +76,40 @@ type ForwardGeocodeRequest struct {
 	Proximity    Coordinate
 	Routing      bool
 	Types        Types
+
+	// CoordinatePrecision overrides the client CoordinatePrecision for Proximity with 1-15 decimals, -1 sends full precision
+	CoordinatePrecision int
+	// ExcludeTypes drops the features of any of these types from the response, e.g. TypeCountry to suppress
+	// overly broad autocomplete results. The filtering happens client-side after the request, it doesn't reduce billing.
+	ExcludeTypes Types
+
+	// SortByProximity reorders the features by distance from Proximity, nearest first, instead of the Mapbox
+	// blend of relevance and distance. Features without a location go last. Ignored without a Proximity.
+	SortByProximity bool
+
+	// PreferPOI is a display preference moving the POI features before the other results, keeping their
+	// relative order, e.g. for "find a business" search bars. The request sent to Mapbox is unchanged.
+	PreferPOI bool
+
+	// FallbackWithoutBBox reissues the request without BBox when the BBox constrained request has no results.
+	// Note that the fallback is billed as a separate request.
+	FallbackWithoutBBox bool
 }
 
 type ForwardGeocodeResponse struct {
-	Type        string     `json:"type"`
-	Query       []string   `json:"query"`
-	Features    []*Feature `json:"features"`
-	Attribution string     `json:"attribution"`
+	Type        string   `json:"type"`
+	Query       []string `json:"query"`
+	Features    Features `json:"features"`
+	Attribution string   `json:"attribution"`
+
+	// BBoxFallback is set when the results come from the FallbackWithoutBBox request
+	BBoxFallback bool `json:"-"`
 }
 
 //////////////////////////////////////////////////////////////////
 
+type Features []*Feature
+
 type Feature struct {
 	ID                string      `json:"id"`
 	Type              string      `json:"type"`
@@ -78,6 +126,34 @@ type Feature struct {
 	Center            []float64   `json:"center"`
 	Geometry          *Geometry   `json:"geometry"`
 	Context           []*Context  `json:"context,omitempty"`
+	// RoutablePoints of geocoding v5 addresses and POIs, requested with Routing
+	RoutablePoints *GeocodingRoutablePoints `json:"routable_points,omitempty"`
+
+	// RawProperties preserves the original properties JSON, to decode fields Properties doesn't model yet
+	RawProperties json.RawMessage `json:"-"`
+
+	// Distance in meters from the reference point given to Features.WithDistancesFrom, e.g. the request proximity
+	Distance float64 `json:"-"`
+}
+
+// UnmarshalJSON decodes the feature and keeps a copy of its properties JSON in RawProperties
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	type feature Feature
+	if err := json.Unmarshal(data, (*feature)(f)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw.Properties) != 0 && string(raw.Properties) != "null" {
+		f.RawProperties = raw.Properties
+	}
+
+	return nil
 }
 
 // TODO: need to properly unmarshal this data. (In some cases) Mapbox returns {} for properties which creates an empty struct
@@ -89,13 +165,181 @@ type Properties struct {
 	Landmark  bool   `json:"landmark,omitempty"`
 	Wikidata  string `json:"wikidata,omitempty"`
 	ShortCode string `json:"short_code,omitempty"`
+
+	// Search Box POI properties
+	Name           string            `json:"name,omitempty"`
+	MapboxID       string            `json:"mapbox_id,omitempty"`
+	FeatureType    string            `json:"feature_type,omitempty"`
+	FullAddress    string            `json:"full_address,omitempty"`
+	PlaceFormatted string            `json:"place_formatted,omitempty"`
+	POICategory    []string          `json:"poi_category,omitempty"`
+	POICategoryIDs []string          `json:"poi_category_ids,omitempty"`
+	Brand          []string          `json:"brand,omitempty"`
+	BrandID        []string          `json:"brand_id,omitempty"`
+	ExternalIDs    map[string]string `json:"external_ids,omitempty"`
+	Context        *SearchBoxContext `json:"context,omitempty"`
+	ETA            float64           `json:"eta,omitempty"` // minutes from the request origin, with ETATypeNavigation
+	// Coordinates is the Search Box location with its routable points, see Feature.RoutablePoint
+	Coordinates *ExtendedCoordinate `json:"coordinates,omitempty"`
+}
+
+// ExtendedCoordinate is the location of a Search Box feature
+type ExtendedCoordinate struct {
+	Latitude       float64         `json:"latitude"`
+	Longitude      float64         `json:"longitude"`
+	Accuracy       string          `json:"accuracy,omitempty"`
+	RoutablePoints []RoutablePoint `json:"routable_points,omitempty"`
+}
+
+// RoutablePoint is where a vehicle can stop to reach a feature, e.g. the street entrance of a building
+type RoutablePoint struct {
+	Name       string // e.g. "default", or the entrance name
+	Coordinate Coordinate
+}
+
+// UnmarshalJSON decodes the {name, latitude, longitude} Search Box routable point
+func (p *RoutablePoint) UnmarshalJSON(data []byte) error {
+	var point struct {
+		Name      string  `json:"name"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}
+	if err := json.Unmarshal(data, &point); err != nil {
+		return err
+	}
+	*p = RoutablePoint{Name: point.Name, Coordinate: Coordinate{Lat: point.Latitude, Lng: point.Longitude}}
+	return nil
+}
+
+// MarshalJSON encodes the routable point in the Search Box format
+func (p RoutablePoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name      string  `json:"name"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}{p.Name, p.Coordinate.Lat, p.Coordinate.Lng})
+}
+
+// GeocodingRoutablePoints are the routable points of a geocoding v5 feature, requested with Routing
+type GeocodingRoutablePoints struct {
+	Points []struct {
+		Coordinates []float64 `json:"coordinates"` // [longitude, latitude]
+	} `json:"points"`
 }
 
 type Geometry struct {
-	Coordinates  []float64 `json:"coordinates"`
+	Coordinates  []float64 `json:"coordinates"` // The Point position, [longitude, latitude].
 	Type         string    `json:"type"`
 	Interpolated bool      `json:"interpolated,omitempty"`
 	Omitted      string    `json:"omitted,omitempty"`
+
+	// Line is the LineString positions, Polygon the Polygon rings and MultiPolygon the polygons of a
+	// MultiPolygon, decoded from the nested coordinates
+	Line         [][]float64     `json:"-"`
+	Polygon      [][][]float64   `json:"-"`
+	MultiPolygon [][][][]float64 `json:"-"`
+}
+
+// UnmarshalJSON decodes the coordinates into Coordinates, Line, Polygon or MultiPolygon depending on the geometry type
+func (g *Geometry) UnmarshalJSON(data []byte) error {
+	type geometry Geometry
+	decoded := struct {
+		*geometry
+		Coordinates json.RawMessage `json:"coordinates"`
+	}{geometry: (*geometry)(g)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if len(decoded.Coordinates) == 0 || string(decoded.Coordinates) == "null" {
+		return nil
+	}
+
+	switch g.Type {
+	case "LineString":
+		return json.Unmarshal(decoded.Coordinates, &g.Line)
+	case "Polygon":
+		return json.Unmarshal(decoded.Coordinates, &g.Polygon)
+	case "MultiPolygon":
+		return json.Unmarshal(decoded.Coordinates, &g.MultiPolygon)
+	default:
+		return json.Unmarshal(decoded.Coordinates, &g.Coordinates)
+	}
+}
+
+// MarshalJSON encodes Line, Polygon or MultiPolygon as the coordinates of the matching geometry types
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	type geometry Geometry
+	var coordinates interface{} = g.Coordinates
+	switch g.Type {
+	case "LineString":
+		coordinates = g.Line
+	case "Polygon":
+		coordinates = g.Polygon
+	case "MultiPolygon":
+		coordinates = g.MultiPolygon
+	}
+	return json.Marshal(struct {
+		Coordinates interface{} `json:"coordinates"`
+		geometry
+	}{coordinates, geometry(g)})
+}
+
+// WKT returns the geometry in Well-Known Text, e.g. "POINT(-117.306786 33.122508)".
+// Point, LineString and Polygon geometries are supported, any other type is reported as an error.
+func (g *Geometry) WKT() (string, error) {
+	if g == nil {
+		return "", fmt.Errorf("missing geometry")
+	}
+
+	switch g.Type {
+	case "Point":
+		if len(g.Coordinates) < 2 {
+			return "", fmt.Errorf("invalid Point coordinates %v", g.Coordinates)
+		}
+		return "POINT(" + wktPosition(g.Coordinates) + ")", nil
+	case "LineString":
+		positions, err := wktPositions(g.Line)
+		if err != nil {
+			return "", err
+		}
+		return "LINESTRING" + positions, nil
+	case "Polygon":
+		if len(g.Polygon) == 0 {
+			return "", fmt.Errorf("missing Polygon rings")
+		}
+		rings := make([]string, 0, len(g.Polygon))
+		for _, r := range g.Polygon {
+			positions, err := wktPositions(r)
+			if err != nil {
+				return "", err
+			}
+			rings = append(rings, positions)
+		}
+		return "POLYGON(" + strings.Join(rings, ",") + ")", nil
+	default:
+		return "", fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+}
+
+// wktPosition returns the position as "{lng} {lat}"
+func wktPosition(position []float64) string {
+	return formatDegrees(position[0]) + " " + formatDegrees(position[1])
+}
+
+// wktPositions returns the positions as "({lng} {lat},{lng} {lat},...)"
+func wktPositions(positions [][]float64) (string, error) {
+	if len(positions) < 2 {
+		return "", fmt.Errorf("expected at least 2 positions, got %v", len(positions))
+	}
+
+	formatted := make([]string, 0, len(positions))
+	for _, position := range positions {
+		if len(position) < 2 {
+			return "", fmt.Errorf("invalid position %v", position)
+		}
+		formatted = append(formatted, wktPosition(position))
+	}
+	return "(" + strings.Join(formatted, ",") + ")", nil
 }
 
 type Context struct {
@@ -107,15 +351,101 @@ type Context struct {
 
 //////////////////////////////////////////////////////////////////
 
+// precision returns the request coordinate precision, falling back to the client default when unset
+func (c *Client) precision(requestPrecision int) int {
+	if requestPrecision != 0 {
+		return requestPrecision
+	}
+	if c.coordinatePrecision == 0 {
+		// client not built by NewClient
+		return -1
+	}
+	return c.coordinatePrecision
+}
+
+// validatePrecision checks precision is unset (0), full (-1) or between 1 and 15 decimals
+func validatePrecision(precision int) error {
+	if precision < -1 || precision > 15 {
+		return fmt.Errorf("coordinate precision must be between 1 and 15, or -1 for full precision, got %v", precision)
+	}
+	return nil
+}
+
+// languageTag matches the BCP 47 subset Mapbox supports: language[-Script][-REGION], e.g. "en", "zh-Hant" or "es-419"
+var languageTag = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z]{4})?(-([a-zA-Z]{2}|[0-9]{3}))?$`)
+
+// validateLanguage checks the comma-separated language tags are well-formed, e.g. "en" and not "english"
+func validateLanguage(language string) error {
+	if language == "" {
+		return nil
+	}
+	for _, tag := range strings.Split(language, ",") {
+		if !languageTag.MatchString(tag) {
+			return fmt.Errorf("invalid language tag %q, expected language[-Script][-REGION], e.g. en or zh-Hant", tag)
+		}
+	}
+	return nil
+}
+
+// limit returns the request limit, falling back to the client default when unset
+func (c *Client) limit(requestLimit int) int {
+	if requestLimit != 0 {
+		return requestLimit
+	}
+	return c.defaultLimit
+}
+
+func (r *ForwardGeocodeRequest) validate() error {
+	if r.SearchText == "" {
+		return fmt.Errorf("missing search text")
+	}
+	if err := validatePrecision(r.CoordinatePrecision); err != nil {
+		return err
+	}
+	if err := validateLanguage(r.Language); err != nil {
+		return err
+	}
+	if r.hasBBox() {
+		if err := r.BBox.validate(); err != nil {
+			return err
+		}
+	}
+	return r.Types.Validate()
+}
+
+// CacheKey returns a canonical key of the request for external caches: its path and sorted query, without the
+// access token and before the client defaults such as DefaultLimit and CoordinatePrecision, so equal requests
+// have equal keys whatever the order their fields were set in. Empty when the request is invalid.
+func (r *ForwardGeocodeRequest) CacheKey() string {
+	req := *r
+	return cacheKey(forwardGeocodeQuery(&Client{}, &req))
+}
+
+func (r *ForwardGeocodeRequest) hasBBox() bool {
+	return r.BBox.Min.Lat != 0 && r.BBox.Min.Lng != 0
+}
+
 // https://docs.mapbox.com/api/search/#forward-geocoding
-func forwardGeocode(ctx context.Context, client *Client, req *ForwardGeocodeRequest) (*ForwardGeocodeResponse, error) {
+func forwardGeocodeQuery(client *Client, req *ForwardGeocodeRequest) (string, url.Values, error) {
+	if err := req.validate(); err != nil {
+		return "", nil, err
+	}
+	if err := client.warn(req.Types.discouraged()); err != nil {
+		return "", nil, err
+	}
+	// the bbox filters the results and the proximity only biases them, a proximity outside is likely a mistake
+	if req.hasBBox() && req.Proximity.Lat != 0 && !req.BBox.Contains(req.Proximity) {
+		if err := client.warn(fmt.Errorf("proximity %v is outside the bbox %v", req.Proximity.WGS84Format(), req.BBox.query())); err != nil {
+			return "", nil, err
+		}
+	}
+
 	relPath := fmt.Sprintf("%v/%v/%v/%v.json", geocodePath, v5, req.Endpoint, url.PathEscape(req.SearchText))
 
 	query := url.Values{}
-	query.Set("access_token", client.apiKey)
 	query.Set("autocomplete", strconv.FormatBool(req.Autocomplete))
-	if req.BBox.Min.Lat != 0 && req.BBox.Min.Lng != 0 {
-		query.Set("bbox", req.BBox.query())
+	if req.hasBBox() {
+		query.Set("bbox", req.BBox.Normalize().query())
 	}
 	if req.Country != "" {
 		query.Set("country", req.Country)
@@ -124,42 +454,181 @@ func forwardGeocode(ctx context.Context, client *Client, req *ForwardGeocodeRequ
 	if req.Language != "" {
 		query.Set("language", req.Language)
 	}
-	if req.Limit != 0 {
-		query.Set("limit", strconv.Itoa(req.Limit))
+	if limit := client.limit(req.Limit); limit != 0 {
+		query.Set("limit", strconv.Itoa(limit))
 	}
 	if req.Proximity.Lat != 0 {
-		query.Set("proximity", req.Proximity.WGS84Format())
+		query.Set("proximity", req.Proximity.format(client.precision(req.CoordinatePrecision)))
 	}
 	query.Set("routing", strconv.FormatBool(req.Routing))
 	if len(req.Types) != 0 {
 		query.Set("types", req.Types.query())
 	}
 
-	apiResponse, err := client.get(ctx, relPath, query)
+	return relPath, query, nil
+}
+
+// forwardGeocodeCacheKey returns the GeocodeCache key of the request, its path with the normalized search text and its query
+func forwardGeocodeCacheKey(req *ForwardGeocodeRequest, query url.Values) string {
+	return fmt.Sprintf("%v/%v/%v/%v.json?%v", geocodePath, v5, req.Endpoint, url.PathEscape(NormalizeAddress(req.SearchText)), query.Encode())
+}
+
+// cachedGet decodes the GeocodeCache body of key into response, or gets relPath and caches its successful body
+func (c *Client) cachedGet(ctx context.Context, key, relPath string, query url.Values, response interface{}) error {
+	if c.geocodeCache != nil {
+		if body, ok := c.geocodeCache.Get(key); ok {
+			if err := json.Unmarshal(body, response); err != nil {
+				return fmt.Errorf("failed to read cached body. %w", err)
+			}
+			return nil
+		}
+	}
+
+	apiResponse, err := c.get(ctx, relPath, query)
+	if err != nil {
+		return err
+	}
+	if c.geocodeCache == nil || apiResponse.StatusCode != http.StatusOK {
+		return c.handleResponse(apiResponse, response, GeocodingRateLimit)
+	}
+
+	body, err := ioutil.ReadAll(apiResponse.Body)
+	apiResponse.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read body. %w", err)
+	}
+	apiResponse.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := c.handleResponse(apiResponse, response, GeocodingRateLimit); err != nil {
+		return err
+	}
+	c.geocodeCache.Set(key, body)
+	return nil
+}
+
+func forwardGeocode(ctx context.Context, client *Client, req *ForwardGeocodeRequest) (*ForwardGeocodeResponse, error) {
+	relPath, query, err := forwardGeocodeQuery(client, req)
 	if err != nil {
 		return nil, err
 	}
 
 	var response ForwardGeocodeResponse
-	if err := client.handleResponse(apiResponse, &response, GeocodingRateLimit); err != nil {
+	// a partial decode keeps the response, the error is returned with it
+	decodeErr := client.cachedGet(ctx, forwardGeocodeCacheKey(req, query), relPath, query, &response)
+	if decodeErr != nil && !isPartialDecode(decodeErr) {
+		return nil, decodeErr
+	}
+	if err := client.checkResponseType(response.Type, featureCollection); err != nil {
 		return nil, err
 	}
 
-	return &response, nil
+	// the fallback is for an empty BBox, not for results filtered out by ExcludeTypes
+	bboxResults := len(response.Features)
+	response.Features = response.Features.excluding(req.ExcludeTypes)
+	if req.Proximity.Lat != 0 {
+		response.Features.WithDistancesFrom(req.Proximity)
+		if req.SortByProximity {
+			response.Features.SortByDistanceFrom(req.Proximity)
+		}
+	}
+	if req.PreferPOI {
+		response.Features.SortByTypePriority(Types{TypePOI})
+	}
+
+	if req.FallbackWithoutBBox && req.hasBBox() && bboxResults == 0 {
+		fallback := *req
+		fallback.BBox = BoundingBox{}
+		fallback.FallbackWithoutBBox = false
+
+		fallbackResponse, err := client.ForwardGeocode(ctx, &fallback)
+		if err != nil && !isPartialDecode(err) {
+			return nil, err
+		}
+		fallbackResponse.BBoxFallback = true
+		return fallbackResponse, err
+	}
+
+	return &response, decodeErr
+}
+
+// https://docs.mapbox.com/api/search/geocoding-v5/#reverse-geocoding
+func (r *ReverseGeocodeRequest) validate() error {
+	if len(r.Coordinates) == 0 {
+		return fmt.Errorf("missing coordinates")
+	}
+	if err := validatePrecision(r.CoordinatePrecision); err != nil {
+		return err
+	}
+	if err := validateLanguage(r.Language); err != nil {
+		return err
+	}
+	if r.Limit < 0 || r.Limit > 5 {
+		return fmt.Errorf("reverse geocoding limit must be between 1 and 5, got %v", r.Limit)
+	}
+	if r.Zoom != nil && (*r.Zoom < 0 || *r.Zoom > 22) {
+		return fmt.Errorf("zoom must be between 0 and 22, got %v", *r.Zoom)
+	}
+	if types := r.types(); r.Limit > 1 && len(types) != 1 {
+		return fmt.Errorf("reverse geocoding limit %v requires exactly one type, got %v", r.Limit, len(types))
+	}
+	if r.AddressableRadius < 0 {
+		return fmt.Errorf("addressable radius must be positive, got %v", r.AddressableRadius)
+	}
+	return r.Types.Validate()
+}
+
+// CacheKey returns the canonical key of the request, see ForwardGeocodeRequest.CacheKey
+func (r *ReverseGeocodeRequest) CacheKey() string {
+	req := *r
+	return cacheKey(reverseGeocodeQuery(&Client{}, &req))
+}
+
+// types returns the requested Types, or the TypesForZoom of the Zoom when they are empty
+func (r *ReverseGeocodeRequest) types() Types {
+	if len(r.Types) == 0 && r.Zoom != nil {
+		return TypesForZoom(*r.Zoom)
+	}
+	return r.Types
 }
 
 // https://docs.mapbox.com/api/search/#reverse-geocoding
-func reverseGeocode(ctx context.Context, client *Client, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error) {
-	relPath := fmt.Sprintf("%v/%v/%v/%v.json", geocodePath, v5, req.Endpoint, req.Coordinates.WGS84Format())
+func reverseGeocodeQuery(client *Client, req *ReverseGeocodeRequest) (string, url.Values, error) {
+	if err := req.validate(); err != nil {
+		return "", nil, err
+	}
+	if err := client.warn(req.Types.discouraged()); err != nil {
+		return "", nil, err
+	}
 
+	relPath := fmt.Sprintf("%v/%v/%v/%v.json", geocodePath, v5, req.Endpoint, req.Coordinates.format(client.precision(req.CoordinatePrecision)))
+
+	types := req.types()
 	query := url.Values{}
-	query.Set("access_token", client.apiKey)
 	query.Set("country", req.Country)
 	query.Set("language", req.Language)
-	query.Set("limit", strconv.Itoa(req.Limit))
+	limit := client.limit(req.Limit)
+	if req.Limit == 0 && limit > 1 && len(types) != 1 {
+		// the client default can't be applied without a single type, keep the Mapbox default of 1
+		limit = 0
+	}
+	if limit != 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
 	query.Set("reverseMode", req.ReverseMode.query())
 	query.Set("routing", strconv.FormatBool(req.Routing))
-	query.Set("types", req.Types.query())
+	query.Set("types", types.query())
+
+	return relPath, query, nil
+}
+
+func reverseGeocode(ctx context.Context, client *Client, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error) {
+	relPath, query, err := reverseGeocodeQuery(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PreferAddressable {
+		return reverseGeocodeAddressable(ctx, client, req)
+	}
 
 	apiResponse, err := client.get(ctx, relPath, query)
 	if err != nil {
@@ -167,9 +636,46 @@ func reverseGeocode(ctx context.Context, client *Client, req *ReverseGeocodeRequ
 	}
 
 	var response ReverseGeocodeResponse
-	if err := client.handleResponse(apiResponse, &response, GeocodingRateLimit); err != nil {
+	decodeErr := client.handleResponse(apiResponse, &response, GeocodingRateLimit)
+	if decodeErr != nil && !isPartialDecode(decodeErr) {
+		return nil, decodeErr
+	}
+	if err := client.checkResponseType(response.Type, featureCollection); err != nil {
 		return nil, err
 	}
+	response.Features = response.Features.excluding(req.ExcludeTypes)
+
+	return &response, decodeErr
+}
+
+// reverseGeocodeAddressable requests the nearest address and falls back to the request without PreferAddressable
+func reverseGeocodeAddressable(ctx context.Context, client *Client, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error) {
+	address := *req
+	address.Types = Types{TypeAddress}
+	address.PreferAddressable = false
+
+	response, err := client.ReverseGeocode(ctx, &address)
+	if err != nil && !isPartialDecode(err) && ctx.Err() != nil {
+		// the caller gave up, there is no point in the fallback
+		return nil, err
+	}
+	if err == nil || isPartialDecode(err) {
+		// an address without a location can't be told within the radius
+		var located Features
+		for _, feature := range response.Features {
+			if _, ok := feature.Coordinate(); feature != nil && ok {
+				located = append(located, feature)
+			}
+		}
+		located.WithDistancesFrom(req.Coordinates[0])
+		if len(located) != 0 && (req.AddressableRadius == 0 || located[0].Distance <= req.AddressableRadius) {
+			response.Features = located
+			response.AddressableMatch = true
+			return response, err
+		}
+	}
 
-	return &response, nil
+	fallback := *req
+	fallback.PreferAddressable = false
+	return client.ReverseGeocode(ctx, &fallback)
 }
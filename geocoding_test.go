@@ -1,7 +1,14 @@
 package mapbox
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -23,3 +30,779 @@ func TestForwardGeocodeURLEncoding(t *testing.T) {
 		SearchText: "query with special chars:/; ",
 	}, `/geocoding/v5/mapbox.places/query%20with%20special%20chars:%2F%3B%20.json?autocomplete=false&fuzzyMatch=false&routing=false`)
 }
+
+func TestReverseGeocodeOne(t *testing.T) {
+	client, requests := mockClient(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"address.1","text":"Main St"}]}`)),
+		},
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`)),
+		},
+	)
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+
+	req := &ReverseGeocodeRequest{Endpoint: EndpointPlaces, Limit: 5, Language: "en"}
+	feature, err := client.ReverseGeocodeOne(context.Background(), Coordinate{Lat: 33.122508, Lng: -117.306786}, req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if feature.ID != "address.1" {
+		t.Errorf("expected feature address.1, got %q", feature.ID)
+	}
+	if req.Limit != 5 {
+		t.Errorf("expected caller request to be left untouched, got limit %v", req.Limit)
+	}
+
+	_, err = client.ReverseGeocodeOne(context.Background(), Coordinate{Lat: 33.122508, Lng: -117.306786}, nil)
+	if err != ErrNoResults {
+		t.Errorf("expected ErrNoResults, got %v", err)
+	}
+}
+
+func TestGeometryWKT(t *testing.T) {
+	tests := []struct {
+		geometry *Geometry
+		expected string
+		err      bool
+	}{
+		{&Geometry{Type: "Point", Coordinates: []float64{-117.306786, 33.122508}}, "POINT(-117.306786 33.122508)", false},
+		{&Geometry{Type: "Point", Coordinates: []float64{0.000001, -0.00002}}, "POINT(0.000001 -0.00002)", false},
+		{&Geometry{Type: "Point", Coordinates: []float64{1}}, "", true},
+		{&Geometry{Type: "LineString"}, "", true},
+		{&Geometry{Type: "LineString", Line: [][]float64{{-117.3, 33.1}, {-117.2, 33.2}}}, "LINESTRING(-117.3 33.1,-117.2 33.2)", false},
+		{&Geometry{Type: "Polygon", Polygon: [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, "POLYGON((0 0,1 0,1 1,0 0))", false},
+		{&Geometry{Type: "Polygon"}, "", true},
+		{&Geometry{Type: "MultiPoint"}, "", true},
+		{nil, "", true},
+	}
+
+	for _, test := range tests {
+		actual, err := test.geometry.WKT()
+		if (err != nil) != test.err {
+			t.Errorf("%+v: expected error %v, got %v", test.geometry, test.err, err)
+		}
+		if actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestGeometryNestedCoordinates(t *testing.T) {
+	data := `{"coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"type":"Polygon"}`
+	var geometry Geometry
+	if err := json.Unmarshal([]byte(data), &geometry); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(geometry.Polygon) != 1 || len(geometry.Polygon[0]) != 4 || geometry.Coordinates != nil {
+		t.Errorf("unexpected geometry %+v", geometry)
+	}
+
+	encoded, err := json.Marshal(geometry)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(encoded) != data {
+		t.Errorf("expected %s, got %s", data, encoded)
+	}
+
+	if wkt, err := (Coordinates{{Lat: 33.1, Lng: -117.3}, {Lat: 33.2, Lng: -117.2}}).WKT(); err != nil || wkt != "LINESTRING(-117.3 33.1,-117.2 33.2)" {
+		t.Errorf("unexpected coordinates WKT %q, %v", wkt, err)
+	}
+}
+
+func TestForwardGeocodeDefaultLimit(t *testing.T) {
+	client, requests := mockClient()
+	client.defaultLimit = 3
+	go client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "coffee"})
+
+	httpReq := <-requests
+	if limit := httpReq.URL.Query().Get("limit"); limit != "3" {
+		t.Errorf("expected default limit 3, got %q", limit)
+	}
+
+	go client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "coffee", Limit: 7})
+
+	httpReq = <-requests
+	if limit := httpReq.URL.Query().Get("limit"); limit != "7" {
+		t.Errorf("expected request limit 7, got %q", limit)
+	}
+}
+
+func TestNewClientDefaultLimitValidation(t *testing.T) {
+	for _, limit := range []int{-1, 11} {
+		if _, err := NewClient(&MapboxConfig{APIKey: "test", DefaultLimit: limit}); err == nil {
+			t.Errorf("expected error for default limit %v", limit)
+		}
+	}
+}
+
+func TestForwardGeocodeFallbackWithoutBBox(t *testing.T) {
+	client, requests := mockClient(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`)),
+		},
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"place.1"}]}`)),
+		},
+	)
+	urls := make(chan string, 2)
+	go func() {
+		for r := range requests {
+			urls <- r.URL.RequestURI()
+		}
+	}()
+	defer close(requests)
+
+	response, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "carlsbad",
+		BBox: BoundingBox{
+			Min: Coordinate{Lat: 33.121217, Lng: -117.310429},
+			Max: Coordinate{Lat: 33.124973, Lng: -117.305054},
+		},
+		FallbackWithoutBBox: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !response.BBoxFallback || len(response.Features) != 1 {
+		t.Errorf("expected fallback response with one feature, got %+v", response)
+	}
+
+	expected := []string{
+		`/geocoding/v5/mapbox.places/carlsbad.json?autocomplete=false&bbox=-117.310429%2C33.121217%2C-117.305054%2C33.124973&fuzzyMatch=false&routing=false`,
+		`/geocoding/v5/mapbox.places/carlsbad.json?autocomplete=false&fuzzyMatch=false&routing=false`,
+	}
+	for _, expectedURL := range expected {
+		if actualURL := <-urls; actualURL != expectedURL {
+			t.Errorf("expected:\n%s, got:\n%s", expectedURL, actualURL)
+		}
+	}
+}
+
+type mapCache map[string][]byte
+
+func (c mapCache) Get(key string) ([]byte, bool) {
+	body, ok := c[key]
+	return body, ok
+}
+
+func (c mapCache) Set(key string, body []byte) {
+	c[key] = body
+}
+
+func TestForwardGeocodeCache(t *testing.T) {
+	client, requests := mockClient(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"address.1"}]}`)),
+		},
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"address.2"}]}`)),
+		},
+	)
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+	cache := mapCache{}
+	client.geocodeCache = cache
+
+	for _, text := range []string{"123 Main St.", "123  main st"} {
+		response, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: text})
+		if err != nil || len(response.Features) != 1 || response.Features[0].ID != "address.1" {
+			t.Errorf("%q: expected the cached address.1, got %+v, %v", text, response, err)
+		}
+	}
+	// a different request isn't served from the cache, and gets the second response
+	response, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "123 main st", Country: "us"})
+	if err != nil || response.Features[0].ID != "address.2" {
+		t.Errorf("expected address.2, got %+v, %v", response, err)
+	}
+
+	if len(cache) != 2 {
+		t.Errorf("expected 2 cache entries, got %v", len(cache))
+	}
+}
+
+func TestForwardGeocodeNear(t *testing.T) {
+	client, requests := mockClient(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"place.1","center":[-71.06,42.36]}]}`)),
+		},
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"poi.1","center":[-71.05,42.35]}]}`)),
+		},
+	)
+	urls := make(chan string, 2)
+	go func() {
+		for r := range requests {
+			urls <- r.URL.RequestURI()
+		}
+	}()
+	defer close(requests)
+
+	req := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "coffee", Country: "us"}
+	response, err := client.ForwardGeocodeNear(context.Background(), req, "Boston")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(response.Features) != 1 || response.Features[0].ID != "poi.1" || req.Proximity.Lat != 0 {
+		t.Errorf("unexpected response %+v", response)
+	}
+
+	expected := []string{
+		`/geocoding/v5/mapbox.places/Boston.json?autocomplete=false&country=us&fuzzyMatch=false&limit=1&routing=false`,
+		`/geocoding/v5/mapbox.places/coffee.json?autocomplete=false&country=us&fuzzyMatch=false&proximity=-71.06%2C42.36&routing=false`,
+	}
+	for _, expectedURL := range expected {
+		if actualURL := <-urls; actualURL != expectedURL {
+			t.Errorf("expected:\n%s, got:\n%s", expectedURL, actualURL)
+		}
+	}
+}
+
+func TestForwardGeocodeFallbackIgnoresExcludeTypes(t *testing.T) {
+	client, requests := mockClient(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"poi.1","place_type":["poi"]}]}`)),
+	})
+	go func() { <-requests }()
+
+	response, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "carlsbad",
+		BBox: BoundingBox{
+			Min: Coordinate{Lat: 33.121217, Lng: -117.310429},
+			Max: Coordinate{Lat: 33.124973, Lng: -117.305054},
+		},
+		ExcludeTypes:        Types{TypePOI},
+		FallbackWithoutBBox: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response.BBoxFallback || len(response.Features) != 0 {
+		t.Errorf("expected the filtered bbox response without fallback, got %+v", response)
+	}
+}
+
+func TestCoarsen(t *testing.T) {
+	client, requests := mockClient(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"place.1","place_type":["place"],"text":"Carlsbad"}]}`)),
+		},
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`)),
+		},
+	)
+	urls := make(chan string, 2)
+	go func() {
+		for r := range requests {
+			urls <- r.URL.RequestURI()
+		}
+	}()
+	defer close(requests)
+
+	coordinate := Coordinate{Lat: 33.122508, Lng: -117.306786}
+	feature, err := client.Coarsen(context.Background(), coordinate, TypePlace)
+	if err != nil || feature.Text != "Carlsbad" {
+		t.Fatalf("expected Carlsbad, got %+v, %v", feature, err)
+	}
+	if url := <-urls; !strings.Contains(url, "limit=1") || !strings.Contains(url, "types=place") {
+		t.Errorf("expected a single place request, got %v", url)
+	}
+
+	if _, err := client.Coarsen(context.Background(), coordinate, TypeRegion); err != ErrNoResults {
+		t.Errorf("expected ErrNoResults, got %v", err)
+	}
+	if _, err := client.Coarsen(context.Background(), coordinate, TypeAddress); err == nil {
+		t.Errorf("expected an error for the address level")
+	}
+}
+
+func TestReverseGeocodePreferAddressable(t *testing.T) {
+	// the address is about 1.1km away, farther than the radius
+	client, requests := mockClient(
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"address.1","place_type":["address"],"center":[-117.3,33.13]}]}`)),
+		},
+		&http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"place.1","place_type":["place"]}]}`)),
+		},
+	)
+	urls := make(chan string, 2)
+	go func() {
+		for r := range requests {
+			urls <- r.URL.RequestURI()
+		}
+	}()
+	defer close(requests)
+
+	req := &ReverseGeocodeRequest{
+		Endpoint:          EndpointPlaces,
+		Coordinates:       Coordinates{{Lat: 33.12, Lng: -117.3}},
+		PreferAddressable: true,
+		AddressableRadius: 500,
+	}
+	response, err := client.ReverseGeocode(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response.AddressableMatch || len(response.Features) != 1 || response.Features[0].ID != "place.1" {
+		t.Errorf("expected the fallback response, got %+v", response)
+	}
+
+	expected := []string{
+		`/geocoding/v5/mapbox.places/-117.3,33.12.json?routing=false&types=address`,
+		`/geocoding/v5/mapbox.places/-117.3,33.12.json?routing=false`,
+	}
+	for _, expectedURL := range expected {
+		if actualURL := <-urls; actualURL != expectedURL {
+			t.Errorf("expected:\n%s, got:\n%s", expectedURL, actualURL)
+		}
+	}
+
+	client, requests = mockClient(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"address.1","place_type":["address"],"center":[-117.3,33.13]}]}`)),
+	})
+	go func() { <-requests }()
+
+	req.AddressableRadius = 2000
+	response, err = client.ReverseGeocode(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !response.AddressableMatch || response.Features[0].ID != "address.1" || response.Features[0].Distance == 0 {
+		t.Errorf("expected the address response, got %+v", response)
+	}
+
+	// an address without a location isn't a match, nor is a failed address request
+	for _, first := range []*http.Response{
+		{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"address.1","place_type":["address"]}]}`))},
+		{StatusCode: 500, Body: ioutil.NopCloser(bytes.NewBufferString(`{"message":"internal error"}`))},
+	} {
+		client, requests = mockClient(first, &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"place.1","place_type":["place"]}]}`)),
+		})
+		go func() {
+			for range requests {
+			}
+		}()
+
+		response, err = client.ReverseGeocode(context.Background(), req)
+		close(requests)
+		if err != nil {
+			t.Fatalf("expected the fallback, got %v", err)
+		}
+		if response.AddressableMatch || len(response.Features) != 1 || response.Features[0].ID != "place.1" {
+			t.Errorf("expected the fallback response, got %+v", response)
+		}
+	}
+}
+
+func TestReverseGeocodeRequestValidation(t *testing.T) {
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}}
+
+	tests := []struct {
+		req *ReverseGeocodeRequest
+		err bool
+	}{
+		{&ReverseGeocodeRequest{Coordinates: coordinates}, false},
+		{&ReverseGeocodeRequest{Coordinates: coordinates, Limit: 1, Types: Types{TypeAddress, TypePlace}}, false},
+		{&ReverseGeocodeRequest{Coordinates: coordinates, Limit: 5, Types: Types{TypeNeighborhood}}, false},
+		{&ReverseGeocodeRequest{Coordinates: coordinates, Limit: 2}, true},
+		{&ReverseGeocodeRequest{Coordinates: coordinates, Limit: 2, Types: Types{TypeAddress, TypePlace}}, true},
+		{&ReverseGeocodeRequest{Coordinates: coordinates, Types: Types{TypeSecondaryAddress}}, true},
+		{&ReverseGeocodeRequest{Coordinates: coordinates, Limit: 6, Types: Types{TypeAddress}}, true},
+		{&ReverseGeocodeRequest{}, true},
+	}
+
+	for _, test := range tests {
+		if err := test.req.validate(); (err != nil) != test.err {
+			t.Errorf("%+v: expected error %v, got %v", test.req, test.err, err)
+		}
+	}
+}
+
+func TestReverseGeocodeZoom(t *testing.T) {
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}}
+	world, street, invalid := 2, 16, 23
+
+	tests := []struct {
+		req   ReverseGeocodeRequest
+		types string
+	}{
+		{ReverseGeocodeRequest{Zoom: &world}, "country"},
+		{ReverseGeocodeRequest{Zoom: &street}, "country,region,district,place,locality,postcode,neighborhood,address,poi"},
+		{ReverseGeocodeRequest{Zoom: &street, Types: Types{TypePlace}}, "place"},
+		{ReverseGeocodeRequest{}, ""},
+	}
+	for _, test := range tests {
+		req := test.req
+		req.Endpoint, req.Coordinates = EndpointPlaces, coordinates
+		_, query, err := reverseGeocodeQuery(&Client{}, &req)
+		if err != nil || query.Get("types") != test.types {
+			t.Errorf("expected types %q, got %q, %v", test.types, query.Get("types"), err)
+		}
+	}
+
+	if err := (&ReverseGeocodeRequest{Coordinates: coordinates, Zoom: &invalid}).validate(); err == nil {
+		t.Errorf("expected an error for zoom %v", invalid)
+	}
+	// the zoom types count towards the single type a limit requires
+	if err := (&ReverseGeocodeRequest{Coordinates: coordinates, Zoom: &world, Limit: 3}).validate(); err != nil {
+		t.Errorf("expected the country zoom to allow a limit, got %v", err)
+	}
+}
+
+func TestReverseGeocodeDefaultLimitRequiresSingleType(t *testing.T) {
+	client, requests := mockClient()
+	client.defaultLimit = 3
+
+	go client.ReverseGeocode(context.Background(), &ReverseGeocodeRequest{
+		Endpoint:    EndpointPlaces,
+		Coordinates: Coordinates{{Lat: 33.122508, Lng: -117.306786}},
+		Types:       Types{TypeAddress, TypePlace},
+	})
+	if limit := (<-requests).URL.Query().Get("limit"); limit != "" {
+		t.Errorf("expected no limit for multiple types, got %q", limit)
+	}
+
+	go client.ReverseGeocode(context.Background(), &ReverseGeocodeRequest{
+		Endpoint:    EndpointPlaces,
+		Coordinates: Coordinates{{Lat: 33.122508, Lng: -117.306786}},
+		Types:       Types{TypeNeighborhood},
+	})
+	if limit := (<-requests).URL.Query().Get("limit"); limit != "3" {
+		t.Errorf("expected default limit 3, got %q", limit)
+	}
+}
+
+func TestForwardGeocodeTypes(t *testing.T) {
+	checkforwardGeocodeRequestURL(t, &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "carlsbad",
+	}, `/geocoding/v5/mapbox.places/carlsbad.json?autocomplete=false&fuzzyMatch=false&routing=false`)
+
+	checkforwardGeocodeRequestURL(t, &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "carlsbad",
+		Types:      TypesAll(),
+	}, `/geocoding/v5/mapbox.places/carlsbad.json?autocomplete=false&fuzzyMatch=false&routing=false&types=country%2Cregion%2Cpostcode%2Cdistrict%2Cplace%2Clocality%2Cneighborhood%2Caddress%2Cpoi`)
+}
+
+func TestForwardGeocodeURL(t *testing.T) {
+	client, _ := NewClient(&MapboxConfig{APIKey: "token"})
+
+	u, err := client.ForwardGeocodeURL(context.Background(), &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "carlsbad",
+		Limit:      1,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "https://api.mapbox.com/geocoding/v5/mapbox.places/carlsbad.json?access_token=token&autocomplete=false&fuzzyMatch=false&limit=1&routing=false"
+	if u.String() != expected {
+		t.Errorf("expected:\n%s, got:\n%s", expected, u.String())
+	}
+
+	if _, err := client.ReverseGeocodeURL(context.Background(), &ReverseGeocodeRequest{Endpoint: EndpointPlaces}); err == nil {
+		t.Errorf("expected validation error")
+	}
+}
+
+func TestForwardGeocodeDistancesFromProximity(t *testing.T) {
+	client, requests := mockClient(&http.Response{
+		StatusCode: 200,
+		Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[
+			{"id":"poi.1","center":[-117.306786,33.122508]},
+			{"id":"poi.2","geometry":{"type":"Point","coordinates":[-117.193443,32.73381]}},
+			{"id":"poi.3"}
+		]}`)),
+	})
+	go func() { <-requests }()
+
+	response, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "coffee",
+		Proximity:  Coordinate{Lat: 33.122508, Lng: -117.306786},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if d := response.Features[0].Distance; d != 0 {
+		t.Errorf("expected 0m, got %v", d)
+	}
+	if d := response.Features[1].Distance; d < 44000 || d > 45000 {
+		t.Errorf("expected ~44.4km, got %v", d)
+	}
+	if d := response.Features[2].Distance; d != 0 {
+		t.Errorf("expected no distance without location, got %v", d)
+	}
+}
+
+func TestGeocodeCoordinatePrecision(t *testing.T) {
+	client, _ := NewClient(&MapboxConfig{APIKey: "token", CoordinatePrecision: 3})
+	proximity := Coordinate{Lat: 33.1225081, Lng: -117.3067869}
+
+	u, err := client.ForwardGeocodeURL(context.Background(), &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "coffee",
+		Proximity:  proximity,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if p := u.Query().Get("proximity"); p != "-117.307,33.123" {
+		t.Errorf("expected rounded proximity, got %q", p)
+	}
+
+	u, err = client.ReverseGeocodeURL(context.Background(), &ReverseGeocodeRequest{
+		Endpoint:            EndpointPlaces,
+		Coordinates:         Coordinates{proximity},
+		CoordinatePrecision: -1,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasSuffix(u.Path, "/-117.3067869,33.1225081.json") {
+		t.Errorf("expected full precision coordinates, got %q", u.Path)
+	}
+
+	for _, precision := range []int{-2, 16} {
+		if _, err := NewClient(&MapboxConfig{APIKey: "test", CoordinatePrecision: precision}); err == nil {
+			t.Errorf("expected error for coordinate precision %v", precision)
+		}
+	}
+}
+
+func TestGeocodeLanguageValidation(t *testing.T) {
+	tests := []struct {
+		language string
+		err      bool
+	}{
+		{"", false},
+		{"en", false},
+		{"en-US", false},
+		{"zh-Hant", false},
+		{"es-419", false},
+		{"fr,de", false},
+		{"english", true},
+		{"en_US", true},
+		{"en,", true},
+		{"zh-Hant-TW-x", true},
+	}
+
+	for _, test := range tests {
+		req := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad", Language: test.language}
+		if err := req.validate(); (err != nil) != test.err {
+			t.Errorf("%q: expected error %v, got %v", test.language, test.err, err)
+		}
+	}
+}
+
+func TestForwardGeocodeProximityOutsideBBox(t *testing.T) {
+	req := &ForwardGeocodeRequest{
+		Endpoint:   EndpointPlaces,
+		SearchText: "coffee",
+		BBox:       BoundingBox{Min: Coordinate{Lat: 33, Lng: -118}, Max: Coordinate{Lat: 34, Lng: -117}},
+		Proximity:  Coordinate{Lat: 40.7, Lng: -74},
+	}
+
+	var logged printfLogger
+	client, _ := NewClient(&MapboxConfig{APIKey: "test", Logger: &logged})
+	u, err := client.ForwardGeocodeURL(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if u.Query().Get("bbox") == "" || u.Query().Get("proximity") == "" {
+		t.Errorf("expected both bbox and proximity, got %v", u.Query())
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], "outside the bbox") {
+		t.Errorf("expected the proximity warning to be logged, got %v", logged)
+	}
+
+	client, _ = NewClient(&MapboxConfig{APIKey: "test", StrictValidation: true})
+	if _, err := client.ForwardGeocodeURL(context.Background(), req); err == nil {
+		t.Errorf("expected a strict validation error")
+	}
+	req.Proximity = Coordinate{Lat: 33.5, Lng: -117.5}
+	if _, err := client.ForwardGeocodeURL(context.Background(), req); err != nil {
+		t.Errorf("expected no error for a proximity inside the bbox, got %v", err)
+	}
+}
+
+func TestReverseGeocodeCoordinateEncoding(t *testing.T) {
+	client, _ := NewClient(&MapboxConfig{APIKey: "token"})
+
+	tests := []struct {
+		coordinate Coordinate
+		expected   string
+	}{
+		{Coordinate{Lat: 33.122508, Lng: -117.306786}, "-117.306786,33.122508"},
+		{Coordinate{Lat: 0.0000001, Lng: -0.0000001}, "-0.0000001,0.0000001"},
+		{Coordinate{Lat: -89.99999999999, Lng: 179.99999999999}, "179.99999999999,-89.99999999999"},
+		{Coordinate{Lat: 90, Lng: -180}, "-180,90"},
+		{Coordinate{Lat: 1e-15, Lng: 0}, "0,0.000000000000001"},
+	}
+
+	for _, test := range tests {
+		u, err := client.ReverseGeocodeURL(context.Background(), &ReverseGeocodeRequest{
+			Endpoint:    EndpointPlaces,
+			Coordinates: Coordinates{test.coordinate},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// v5 takes the coordinate as a single {longitude},{latitude} path segment, with no separate query params
+		if expected := "/geocoding/v5/mapbox.places/" + test.expected + ".json"; u.Path != expected {
+			t.Errorf("expected %q, got %q", expected, u.Path)
+		}
+		if formatted := (Coordinates{test.coordinate}).WGS84Format(); strings.ContainsAny(formatted, "eE") {
+			t.Errorf("unexpected exponent notation in %q", formatted)
+		}
+		query := u.Query()
+		if _, ok := query["longitude"]; ok {
+			t.Errorf("unexpected longitude param in %q", u.RawQuery)
+		}
+		if _, ok := query["latitude"]; ok {
+			t.Errorf("unexpected latitude param in %q", u.RawQuery)
+		}
+	}
+}
+
+func TestGeocodeExcludeTypes(t *testing.T) {
+	body := `{"type":"FeatureCollection","features":[
+		{"id":"country.1","place_type":["country"]},
+		{"id":"place.1","place_type":["place"]},
+		{"id":"region.1","place_type":["region","place"]},
+		{"id":"poi.1","place_type":["poi"]}
+	]}`
+	client, requests := mockClient(
+		&http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))},
+		&http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(body))},
+	)
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+
+	forward, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{
+		Endpoint:     EndpointPlaces,
+		SearchText:   "carlsbad",
+		ExcludeTypes: Types{TypeCountry, TypeRegion},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(forward.Features) != 2 || forward.Features[0].ID != "place.1" || forward.Features[1].ID != "poi.1" {
+		t.Errorf("unexpected features %v", forward.Features)
+	}
+
+	reverse, err := client.ReverseGeocode(context.Background(), &ReverseGeocodeRequest{
+		Endpoint:     EndpointPlaces,
+		Coordinates:  Coordinates{{Lat: 33.1, Lng: -117.3}},
+		ExcludeTypes: Types{TypePOI},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(reverse.Features) != 3 {
+		t.Errorf("unexpected features %v", reverse.Features)
+	}
+}
+
+func TestForwardGeocodePreferPOI(t *testing.T) {
+	client, requests := mockClient(&http.Response{
+		StatusCode: 200,
+		Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[` +
+			`{"id":"address.1","place_type":["address"]},{"id":"poi.1","place_type":["poi"]},{"id":"poi.2","place_type":["poi"]}]}`)),
+	})
+	captured := make(chan *http.Request, 1)
+	go func() { captured <- <-requests }()
+
+	response, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "starbucks", PreferPOI: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var ids []string
+	for _, feature := range response.Features {
+		ids = append(ids, feature.ID)
+	}
+	if expected := []string{"poi.1", "poi.2", "address.1"}; !equalStrings(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+	if query := (<-captured).URL.RawQuery; strings.Contains(query, "poi") {
+		t.Errorf("expected the request to be unchanged, got %q", query)
+	}
+}
+
+func TestForwardGeocodeLenientDecoding(t *testing.T) {
+	body := `{"type":"FeatureCollection","features":[{"id":"place.1"},{"id":"place.2","center":"oops"},{"id":"place.3"},{"id":4}]}`
+	response := func() *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": {"application/vnd.geo+json"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		}
+	}
+	req := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}
+
+	// all or nothing by default
+	client, requests := mockClient(response())
+	go func() { <-requests }()
+	if _, err := client.ForwardGeocode(context.Background(), req); err == nil || isPartialDecode(err) {
+		t.Errorf("expected a decoding error, got %v", err)
+	}
+
+	client, requests = mockClient(response())
+	go func() { <-requests }()
+	client.lenient = true
+	geocoded, err := client.ForwardGeocode(context.Background(), req)
+	var partialErr PartialDecodeError
+	if !errors.As(err, &partialErr) || !reflect.DeepEqual(partialErr.Indices, []int{1, 3}) || partialErr.Total != 4 {
+		t.Fatalf("expected the features 1 and 3 to fail, got %v", err)
+	}
+	if geocoded == nil || geocoded.Type != featureCollection || len(geocoded.Features) != 2 ||
+		geocoded.Features[0].ID != "place.1" || geocoded.Features[1].ID != "place.3" {
+		t.Errorf("expected the decoded features, got %+v", geocoded)
+	}
+
+	// a malformed response is still an error
+	client, requests = mockClient(&http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":{}}`)),
+	})
+	go func() { <-requests }()
+	client.lenient = true
+	if _, err := client.ForwardGeocode(context.Background(), req); err == nil || isPartialDecode(err) {
+		t.Errorf("expected a decoding error, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package mapbox
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+const gpxNamespace = "http://www.topografix.com/GPX/1/1"
+
+type gpxDocument struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Namespace string        `xml:"xmlns,attr"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Track     gpxTrack      `xml:"trk"`
+}
+
+type gpxWaypoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Name string `xml:"name,omitempty"`
+}
+
+type gpxTrack struct {
+	Segment struct {
+		Points []gpxWaypoint `xml:"trkpt"`
+	} `xml:"trkseg"`
+}
+
+// GPX returns the route as a GPX 1.1 document, its Coordinates as a track and its Waypoints, when
+// requested with WaypointsPerRoute, as named waypoints. Mapbox routes carry no elevation, so none is
+// written. Returns an error when the route has no decoded geometry.
+func (r *Route) GPX() (string, error) {
+	if len(r.Coordinates) == 0 {
+		return "", fmt.Errorf("route has no geometry")
+	}
+
+	document := gpxDocument{Version: "1.1", Creator: "go-mapbox", Namespace: gpxNamespace}
+	for _, waypoint := range r.Waypoints {
+		if len(waypoint.Location) < 2 {
+			continue
+		}
+		document.Waypoints = append(document.Waypoints, gpxWaypoint{
+			Lat:  formatDegrees(waypoint.Location[1]),
+			Lon:  formatDegrees(waypoint.Location[0]),
+			Name: waypoint.Name,
+		})
+	}
+	for _, c := range r.Coordinates {
+		document.Track.Segment.Points = append(document.Track.Segment.Points, gpxWaypoint{Lat: formatDegrees(c.Lat), Lon: formatDegrees(c.Lng)})
+	}
+
+	data, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode GPX. %w", err)
+	}
+	return xml.Header + string(data) + "\n", nil
+}
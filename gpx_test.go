@@ -0,0 +1,61 @@
+package mapbox
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestRouteGPX(t *testing.T) {
+	route := &Route{
+		Coordinates: Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.73381, Lng: -117.193443}},
+		Waypoints: []Waypoint{
+			{Name: "Carlsbad & <Main>", Location: []float64{-117.306786, 33.122508}},
+			{Name: "San Diego", Location: []float64{-117.193443, 32.73381}},
+		},
+	}
+
+	gpx, err := route.GPX()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := xml.Header + `<gpx version="1.1" creator="go-mapbox" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="33.122508" lon="-117.306786">
+    <name>Carlsbad &amp; &lt;Main&gt;</name>
+  </wpt>
+  <wpt lat="32.73381" lon="-117.193443">
+    <name>San Diego</name>
+  </wpt>
+  <trk>
+    <trkseg>
+      <trkpt lat="33.122508" lon="-117.306786"></trkpt>
+      <trkpt lat="32.73381" lon="-117.193443"></trkpt>
+    </trkseg>
+  </trk>
+</gpx>
+`
+	if gpx != expected {
+		t.Errorf("expected:\n%s, got:\n%s", expected, gpx)
+	}
+
+	var decoded struct {
+		XMLName xml.Name
+		Points  []struct {
+			Lat string `xml:"lat,attr"`
+		} `xml:"trk>trkseg>trkpt"`
+	}
+	if err := xml.Unmarshal([]byte(gpx), &decoded); err != nil {
+		t.Fatalf("expected valid XML, got %v", err)
+	}
+	if decoded.XMLName.Space != "http://www.topografix.com/GPX/1/1" || len(decoded.Points) != 2 {
+		t.Errorf("unexpected decoded GPX %+v", decoded)
+	}
+	if strings.Contains(gpx, "<ele>") {
+		t.Errorf("expected no elevation")
+	}
+
+	if _, err := (&Route{}).GPX(); err == nil {
+		t.Errorf("expected an error without geometry")
+	}
+}
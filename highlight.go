@@ -0,0 +1,78 @@
+package mapbox
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Range is a half-open [Start, End) byte range of a string
+type Range struct {
+	Start int
+	End   int
+}
+
+// HighlightText returns the text autocomplete suggestions display: the Search Box name, or the geocoding place name
+func (f *Feature) HighlightText() string {
+	if f == nil {
+		return ""
+	}
+	if f.Properties != nil && f.Properties.Name != "" {
+		return f.Properties.Name
+	}
+	return f.PlaceName
+}
+
+// HighlightRanges returns the ranges of HighlightText matching the query, to bold them in an autocomplete
+// dropdown. Mapbox doesn't return match offsets, a word matches when it starts with one of the query words,
+// ignoring case, e.g. "carl" highlights "Carl" in "Carlsbad". The ranges are sorted and don't overlap.
+func (f *Feature) HighlightRanges(query string) []Range {
+	query = strings.ToLower(query)
+	var tokens []string
+	for _, token := range words(query) {
+		tokens = append(tokens, query[token.Start:token.End])
+	}
+
+	var ranges []Range
+	text := f.HighlightText()
+	for _, word := range words(text) {
+		lower := strings.ToLower(text[word.Start:word.End])
+		longest := 0
+		for _, token := range tokens {
+			if n := utf8.RuneCountInString(token); n > longest && strings.HasPrefix(lower, token) {
+				longest = n
+			}
+		}
+		if longest == 0 {
+			continue
+		}
+
+		// the matched prefix in runes, lowercasing can change the byte length
+		end := word.Start
+		for i := 0; i < longest; i++ {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			end += size
+		}
+		ranges = append(ranges, Range{Start: word.Start, End: end})
+	}
+	return ranges
+}
+
+// words returns the ranges of the letter and digit runs of s
+func words(s string) []Range {
+	var ranges []Range
+	start := -1
+	for i, r := range s {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && start < 0 {
+			start = i
+		} else if !inWord && start >= 0 {
+			ranges = append(ranges, Range{Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ranges = append(ranges, Range{Start: start, End: len(s)})
+	}
+	return ranges
+}
@@ -0,0 +1,31 @@
+package mapbox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFeatureHighlightRanges(t *testing.T) {
+	geocoded := &Feature{PlaceName: "Carlsbad Village Drive, Carlsbad, California"}
+	searchBox := &Feature{PlaceName: "ignored", Properties: &Properties{Name: "Café Carlsbad"}}
+
+	tests := []struct {
+		feature  *Feature
+		query    string
+		expected []Range
+	}{
+		{geocoded, "carl vill", []Range{{0, 4}, {9, 13}, {24, 28}}},
+		{geocoded, "CARLSBAD, ca", []Range{{0, 8}, {24, 32}, {34, 36}}},
+		{geocoded, "drive-thru", []Range{{17, 22}}},
+		{geocoded, "   ", nil},
+		{geocoded, "oceanside", nil},
+		{searchBox, "CAFÉ", []Range{{0, 5}}},
+		{searchBox, "ca", []Range{{0, 2}, {6, 8}}},
+	}
+
+	for _, test := range tests {
+		if ranges := test.feature.HighlightRanges(test.query); !reflect.DeepEqual(ranges, test.expected) {
+			t.Errorf("%q in %q: expected %v, got %v", test.query, test.feature.HighlightText(), test.expected, ranges)
+		}
+	}
+}
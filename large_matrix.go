@@ -0,0 +1,251 @@
+package mapbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// each block request holds one group of sources followed by one group of destinations,
+	// keeping it within the Matrix coordinate limit
+	matrixBlockSize        = matrixMaxCoordinates / 2
+	matrixTrafficBlockSize = matrixTrafficMaxCoordinates / 2
+)
+
+// LargeMatrix builds the full NxN durations and distances matrices for points, which may exceed the Matrix coordinate
+// limit. The problem is split into source/destination blocks that are requested with up to concurrency requests in
+// flight, every block going through the client rate limiting. The first error cancels the outstanding blocks and is returned.
+func (c *Client) LargeMatrix(ctx context.Context, points Coordinates, profile Profile, concurrency int) (*DirectionsMatrixResponse, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("large matrix requires at least 2 points, got %v", len(points))
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	blockSize := matrixBlockSize
+	if profile == ProfileDrivingTraffic {
+		blockSize = matrixTrafficBlockSize
+	}
+
+	n := len(points)
+	response := &DirectionsMatrixResponse{
+		Code:      "Ok",
+		Durations: make([][]*float64, n),
+		Distances: make([][]*float64, n),
+	}
+	for i := range response.Durations {
+		response.Durations[i] = make([]*float64, n)
+		response.Distances[i] = make([]*float64, n)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		slots    = make(chan struct{}, concurrency)
+	)
+
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+blocks:
+	for sourceStart := 0; sourceStart < n; sourceStart += blockSize {
+		for destinationStart := 0; destinationStart < n; destinationStart += blockSize {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				fail(ctx.Err())
+				break blocks
+			}
+
+			wg.Add(1)
+			go func(sourceStart, destinationStart int) {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				if err := c.matrixBlock(ctx, response, points, profile, sourceStart, destinationStart, blockSize); err != nil {
+					fail(err)
+				}
+			}(sourceStart, destinationStart)
+		}
+	}
+
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return response, nil
+}
+
+// matrixBlock requests the block of sources [sourceStart, sourceStart+blockSize) to destinations
+// [destinationStart, destinationStart+blockSize) and copies it into the full matrices. Blocks never
+// share cells, so no locking is needed. Coordinates are sent as sources followed by destinations, on
+// the diagonal the same points appear twice which keeps the block layout uniform.
+func (c *Client) matrixBlock(ctx context.Context, response *DirectionsMatrixResponse, points Coordinates, profile Profile, sourceStart, destinationStart, blockSize int) error {
+	sources := points[sourceStart:minInt(sourceStart+blockSize, len(points))]
+	destinations := points[destinationStart:minInt(destinationStart+blockSize, len(points))]
+
+	req := &DirectionsMatrixRequest{
+		Profile:     profile,
+		Coordinates: make(Coordinates, 0, len(sources)+len(destinations)),
+		Annotations: Annotations{AnnotationDuration, AnnotationDistance},
+	}
+	req.Coordinates = append(req.Coordinates, sources...)
+	req.Coordinates = append(req.Coordinates, destinations...)
+	for i := range sources {
+		req.Sources = append(req.Sources, i)
+	}
+	for i := range destinations {
+		req.Destinations = append(req.Destinations, len(sources)+i)
+	}
+
+	block, err := c.DirectionsMatrix(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to request matrix block %v,%v. %w", sourceStart, destinationStart, err)
+	}
+	if len(block.Durations) != len(sources) || len(block.Distances) != len(sources) {
+		return fmt.Errorf("unexpected matrix block %v,%v size, expected %v rows", sourceStart, destinationStart, len(sources))
+	}
+
+	for i := range sources {
+		if len(block.Durations[i]) != len(destinations) || len(block.Distances[i]) != len(destinations) {
+			return fmt.Errorf("unexpected matrix block %v,%v size, expected %v columns", sourceStart, destinationStart, len(destinations))
+		}
+		copy(response.Durations[sourceStart+i][destinationStart:], block.Durations[i])
+		copy(response.Distances[sourceStart+i][destinationStart:], block.Distances[i])
+	}
+
+	return nil
+}
+
+// MultiModalMatrix builds the NxN durations and distances matrices of points where each pair has its own profile,
+// legProfiles[i][j] from points[i] to points[j], e.g. walking for short hops and driving otherwise. An empty
+// profile leaves the pair null. One Matrix is requested per profile over the points its pairs use, or a LargeMatrix
+// over all points when they exceed the Matrix coordinate limit.
+// Note that each profile is billed as a separate matrix, including the elements assigned to other profiles.
+func (c *Client) MultiModalMatrix(ctx context.Context, points Coordinates, legProfiles [][]Profile) (*DirectionsMatrixResponse, error) {
+	n := len(points)
+	if n < 2 {
+		return nil, fmt.Errorf("multimodal matrix requires at least 2 points, got %v", n)
+	}
+	if len(legProfiles) != n {
+		return nil, fmt.Errorf("leg profiles must have a row per point, expected %v, got %v", n, len(legProfiles))
+	}
+
+	// the profiles in first use order, with the sources and destinations of their pairs
+	var profiles []Profile
+	sources := make(map[Profile][]bool)
+	destinations := make(map[Profile][]bool)
+	for i, row := range legProfiles {
+		if len(row) != n {
+			return nil, fmt.Errorf("leg profiles row %v must have a column per point, expected %v, got %v", i, n, len(row))
+		}
+		for j, profile := range row {
+			if profile == "" {
+				continue
+			}
+			if _, ok := sources[profile]; !ok {
+				profiles = append(profiles, profile)
+				sources[profile] = make([]bool, n)
+				destinations[profile] = make([]bool, n)
+			}
+			sources[profile][i] = true
+			destinations[profile][j] = true
+		}
+	}
+
+	response := &DirectionsMatrixResponse{
+		Code:      "Ok",
+		Durations: make([][]*float64, n),
+		Distances: make([][]*float64, n),
+	}
+	for i := range response.Durations {
+		response.Durations[i] = make([]*float64, n)
+		response.Distances[i] = make([]*float64, n)
+	}
+
+	for _, profile := range profiles {
+		matrix, rows, columns, err := c.profileMatrix(ctx, points, profile, sources[profile], destinations[profile])
+		if err != nil {
+			return nil, fmt.Errorf("failed to request the %v matrix. %w", profile, err)
+		}
+		for i, row := range legProfiles {
+			for j := range row {
+				if row[j] != profile {
+					continue
+				}
+				response.Durations[i][j] = cell(matrix.Durations, rows[i], columns[j])
+				response.Distances[i][j] = cell(matrix.Distances, rows[i], columns[j])
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// profileMatrix requests the matrix of the points used as sources and destinations, returning the row and column
+// of every point in it. The points are restricted when they fit in a Matrix request, else all of them are requested.
+func (c *Client) profileMatrix(ctx context.Context, points Coordinates, profile Profile, sources, destinations []bool) (*DirectionsMatrixResponse, []int, []int, error) {
+	rows := make([]int, len(points))
+	columns := make([]int, len(points))
+
+	req := &DirectionsMatrixRequest{
+		Profile:     profile,
+		Annotations: Annotations{AnnotationDuration, AnnotationDistance},
+	}
+	for i := range points {
+		if !sources[i] && !destinations[i] {
+			continue
+		}
+		if sources[i] {
+			rows[i] = len(req.Sources)
+			req.Sources = append(req.Sources, len(req.Coordinates))
+		}
+		if destinations[i] {
+			columns[i] = len(req.Destinations)
+			req.Destinations = append(req.Destinations, len(req.Coordinates))
+		}
+		req.Coordinates = append(req.Coordinates, points[i])
+	}
+
+	if err := req.validate(); err != nil {
+		var limitErr MatrixLimitError
+		if !errors.As(err, &limitErr) {
+			return nil, nil, nil, err
+		}
+		matrix, err := c.LargeMatrix(ctx, points, profile, 1)
+		for i := range points {
+			rows[i], columns[i] = i, i
+		}
+		return matrix, rows, columns, err
+	}
+
+	matrix, err := c.DirectionsMatrix(ctx, req)
+	return matrix, rows, columns, err
+}
+
+// cell returns the matrix cell at row, column, nil when the annotation is missing
+func cell(matrix [][]*float64, row, column int) *float64 {
+	if row >= len(matrix) || column >= len(matrix[row]) {
+		return nil
+	}
+	return matrix[row][column]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
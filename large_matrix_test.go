@@ -0,0 +1,166 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// matrixBlockServer answers Matrix requests with duration = source*1000 + destination and
+// distance = duration*10, where the point index is encoded as the coordinate longitude
+func matrixBlockServer(t *testing.T, requests *int32, failAfter int32) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if n := atomic.AddInt32(requests, 1); failAfter > 0 && n > failAfter {
+					return &http.Response{StatusCode: 422, Body: ioutil.NopCloser(bytes.NewBufferString(`{"message":"nope"}`))}, nil
+				}
+
+				parts := strings.Split(r.URL.Path, "/")
+				coordinates, err := ParseCoordinates(parts[len(parts)-1])
+				if err != nil {
+					t.Errorf("unexpected coordinates %v", err)
+				}
+				if len(coordinates) > 25 {
+					t.Errorf("block exceeds the coordinate limit with %v coordinates", len(coordinates))
+				}
+
+				indices := func(param string) []int {
+					var res []int
+					for _, s := range strings.Split(r.URL.Query().Get(param), ";") {
+						i, _ := strconv.Atoi(s)
+						res = append(res, int(coordinates[i].Lng))
+					}
+					return res
+				}
+
+				var response DirectionsMatrixResponse
+				for _, source := range indices("sources") {
+					var durations, distances []*float64
+					for _, destination := range indices("destinations") {
+						duration := float64(source*1000 + destination)
+						distance := duration * 10
+						durations = append(durations, &duration)
+						distances = append(distances, &distance)
+					}
+					response.Durations = append(response.Durations, durations)
+					response.Distances = append(response.Distances, distances)
+				}
+
+				body, _ := json.Marshal(response)
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBuffer(body))}, nil
+			}),
+		},
+	}
+}
+
+func TestLargeMatrix(t *testing.T) {
+	points := make(Coordinates, 30)
+	for i := range points {
+		points[i] = Coordinate{Lat: 1, Lng: float64(i)}
+	}
+
+	var requests int32
+	client := matrixBlockServer(t, &requests, 0)
+
+	response, err := client.LargeMatrix(context.Background(), points, ProfileDriving, 4)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// 30 points in blocks of 12 -> 3x3 blocks
+	if requests != 9 {
+		t.Errorf("expected 9 block requests, got %v", requests)
+	}
+	for source := range points {
+		for destination := range points {
+			duration := response.Durations[source][destination]
+			distance := response.Distances[source][destination]
+			if duration == nil || *duration != float64(source*1000+destination) {
+				t.Fatalf("unexpected duration at %v,%v: %v", source, destination, duration)
+			}
+			if distance == nil || *distance != *duration*10 {
+				t.Fatalf("unexpected distance at %v,%v: %v", source, destination, distance)
+			}
+		}
+	}
+}
+
+func TestLargeMatrixError(t *testing.T) {
+	points := make(Coordinates, 30)
+	for i := range points {
+		points[i] = Coordinate{Lat: 1, Lng: float64(i)}
+	}
+
+	var requests int32
+	client := matrixBlockServer(t, &requests, 2)
+
+	_, err := client.LargeMatrix(context.Background(), points, ProfileDrivingTraffic, 2)
+	var mapboxError MapboxError
+	if !errors.As(err, &mapboxError) || mapboxError.StatusCode != 422 {
+		t.Errorf("expected the block error, got %v", err)
+	}
+}
+
+func TestMultiModalMatrix(t *testing.T) {
+	points := make(Coordinates, 4)
+	for i := range points {
+		points[i] = Coordinate{Lat: 1, Lng: float64(i)}
+	}
+	// walking between the first two points, driving elsewhere, nothing on the diagonal
+	legProfiles := make([][]Profile, len(points))
+	for i := range legProfiles {
+		legProfiles[i] = make([]Profile, len(points))
+		for j := range legProfiles[i] {
+			switch {
+			case i == j:
+			case i < 2 && j < 2:
+				legProfiles[i][j] = ProfileWalking
+			default:
+				legProfiles[i][j] = ProfileDriving
+			}
+		}
+	}
+
+	var requests int32
+	client := matrixBlockServer(t, &requests, 0)
+	profiles := make(map[string]int)
+	transport := client.httpClient.(*http.Client).Transport
+	client.httpClient = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		profiles[strings.Split(r.URL.Path, "/")[4]]++
+		return transport.RoundTrip(r)
+	})}
+
+	response, err := client.MultiModalMatrix(context.Background(), points, legProfiles)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if profiles["walking"] != 1 || profiles["driving"] != 1 {
+		t.Errorf("expected one matrix per profile, got %v", profiles)
+	}
+	for source := range points {
+		for destination := range points {
+			duration := response.Durations[source][destination]
+			if source == destination {
+				if duration != nil {
+					t.Errorf("expected no duration on the diagonal, got %v", *duration)
+				}
+				continue
+			}
+			if duration == nil || *duration != float64(source*1000+destination) {
+				t.Errorf("unexpected duration at %v,%v: %v", source, destination, duration)
+			}
+		}
+	}
+
+	if _, err := client.MultiModalMatrix(context.Background(), points, legProfiles[:2]); err == nil {
+		t.Errorf("expected an error for missing leg profiles")
+	}
+}
@@ -0,0 +1,60 @@
+package mapbox
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseMeta is the HTTP metadata of a response, see ForwardGeocodeWithMeta
+type ResponseMeta struct {
+	StatusCode int
+	RequestID  string // the X-Request-Id header, useful when contacting Mapbox support
+	// Rate limit headers. Mapbox reports the limit per interval and when it resets, not the remaining requests.
+	RateLimitLimit    int
+	RateLimitInterval time.Duration
+	RateLimitReset    time.Time
+	// Latency of the request, retries and token refreshes included
+	Latency time.Duration
+	Header  http.Header
+}
+
+// withResponseMeta returns a context collecting the metadata of the responses of its requests
+func withResponseMeta(ctx context.Context) (context.Context, *ResponseMeta) {
+	meta := &ResponseMeta{}
+	return context.WithValue(ctx, metaKey, meta), meta
+}
+
+// responseMeta returns the metadata collected for ctx, nil when not collected
+func responseMeta(ctx context.Context) *ResponseMeta {
+	meta, _ := ctx.Value(metaKey).(*ResponseMeta)
+	return meta
+}
+
+func (m *ResponseMeta) fill(response *http.Response, latency time.Duration) {
+	header := response.Header
+	*m = ResponseMeta{
+		StatusCode: response.StatusCode,
+		RequestID:  header.Get("X-Request-Id"),
+		Latency:    latency,
+		Header:     header,
+	}
+	if limit, err := strconv.Atoi(header.Get("X-Rate-Limit-Limit")); err == nil {
+		m.RateLimitLimit = limit
+	}
+	if interval, err := strconv.Atoi(header.Get("X-Rate-Limit-Interval")); err == nil {
+		m.RateLimitInterval = time.Duration(interval) * time.Second
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+		m.RateLimitReset = time.Unix(reset, 0)
+	}
+}
+
+// received returns the metadata, nil when no response was received
+func (m *ResponseMeta) received() *ResponseMeta {
+	if m.StatusCode == 0 {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,40 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestForwardGeocodeWithMeta(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Request-Id", "req-123")
+	header.Set("X-Rate-Limit-Limit", "600")
+	header.Set("X-Rate-Limit-Interval", "60")
+	header.Set("X-Rate-Limit-Reset", "1700000000")
+	client, requests := mockClient(&http.Response{
+		StatusCode: 200,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`)),
+	})
+	go func() { <-requests }()
+
+	response, meta, err := client.ForwardGeocodeWithMeta(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response == nil || meta == nil {
+		t.Fatalf("expected a response and its metadata")
+	}
+	if meta.StatusCode != 200 || meta.RequestID != "req-123" || meta.RateLimitLimit != 600 ||
+		meta.RateLimitInterval != time.Minute || !meta.RateLimitReset.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected metadata %+v", meta)
+	}
+
+	if _, meta, err := client.ForwardGeocodeWithMeta(context.Background(), &ForwardGeocodeRequest{}); err == nil || meta != nil {
+		t.Errorf("expected a validation error without metadata, got %+v, %v", meta, err)
+	}
+}
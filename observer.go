@@ -0,0 +1,43 @@
+package mapbox
+
+import (
+	"context"
+	"time"
+)
+
+// RequestInfo describes a request sent to Mapbox, for the MapboxConfig Observer
+type RequestInfo struct {
+	Method     string
+	URL        string // redacted, see RedactURL
+	StatusCode int    // 0 when the request failed before a response
+	Duration   time.Duration
+	Err        error
+	Tag        string // the WithTag tag of the request context, e.g. a tenant for cost attribution
+}
+
+type contextKey int
+
+const (
+	tagKey contextKey = iota
+	metaKey
+)
+
+// WithTag returns a context tagging the requests made with it, the tag is passed to the Observer and never sent to Mapbox
+func WithTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, tagKey, tag)
+}
+
+// tag returns the WithTag tag of ctx, empty when untagged
+func tag(ctx context.Context) string {
+	t, _ := ctx.Value(tagKey).(string)
+	return t
+}
+
+// observe reports the request to the observer, if any
+func (c *Client) observe(ctx context.Context, info RequestInfo) {
+	if c.observer == nil {
+		return
+	}
+	info.Tag = tag(ctx)
+	c.observer(info)
+}
@@ -0,0 +1,45 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClientObserverTag(t *testing.T) {
+	var observed []RequestInfo
+	client, err := NewClient(&MapboxConfig{
+		APIKey: "secret-token",
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`))}, nil
+		})},
+		Observer: func(info RequestInfo) { observed = append(observed, info) },
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := WithTag(context.Background(), "tenant-42")
+	if _, err := client.ForwardGeocode(ctx, &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(observed) != 2 {
+		t.Fatalf("expected 2 observed requests, got %v", len(observed))
+	}
+	if info := observed[0]; info.Tag != "tenant-42" || info.StatusCode != 200 || info.Method != http.MethodGet || info.Err != nil {
+		t.Errorf("unexpected request info %+v", info)
+	}
+	if strings.Contains(observed[0].URL, "secret-token") || strings.Contains(observed[0].URL, "tenant-42") {
+		t.Errorf("expected the redacted URL without the tag, got %q", observed[0].URL)
+	}
+	if observed[1].Tag != "" {
+		t.Errorf("expected an untagged request, got %q", observed[1].Tag)
+	}
+}
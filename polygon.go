@@ -0,0 +1,189 @@
+package mapbox
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// earthRadius is the WGS84 equatorial radius in meters
+const earthRadius = 6378137.0
+
+// PolygonAreaSquareMeters returns the area of a closed ring on the sphere, in square meters.
+// The orientation of the ring doesn't matter.
+func PolygonAreaSquareMeters(ring []Coordinate) float64 {
+	n := len(ring)
+	if n < 3 {
+		return 0
+	}
+
+	// Chamberlain & Duquette, "Some Algorithms for Polygons on a Sphere"
+	var total float64
+	for i := 0; i < n; i++ {
+		lower := ring[i]
+		middle := ring[(i+1)%n]
+		upper := ring[(i+2)%n]
+		total += (radians(upper.Lng) - radians(lower.Lng)) * math.Sin(radians(middle.Lat))
+	}
+
+	return math.Abs(total * earthRadius * earthRadius / 2)
+}
+
+// PolygonArea returns the area of a polygon in square meters.
+// The first ring is the outer boundary, any following rings are holes and are subtracted.
+func PolygonArea(rings [][]Coordinate) float64 {
+	if len(rings) == 0 {
+		return 0
+	}
+
+	area := PolygonAreaSquareMeters(rings[0])
+	for _, hole := range rings[1:] {
+		area -= PolygonAreaSquareMeters(hole)
+	}
+	return math.Max(area, 0)
+}
+
+// Contains reports whether c is inside the Polygon or MultiPolygon geometry, points inside a hole are outside.
+// Points exactly on an edge may be reported either way. Other geometry types are reported as an error.
+func (g *Geometry) Contains(c Coordinate) (bool, error) {
+	if g == nil {
+		return false, fmt.Errorf("missing geometry")
+	}
+
+	switch g.Type {
+	case "Polygon":
+		return polygonContains(g.Polygon, c), nil
+	case "MultiPolygon":
+		for _, polygon := range g.MultiPolygon {
+			if polygonContains(polygon, c) {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("%q geometries have no area", g.Type)
+	}
+}
+
+// polygonContains reports whether c is inside the outer ring and none of the holes
+func polygonContains(rings [][][]float64, c Coordinate) bool {
+	if len(rings) == 0 || !ringContains(rings[0], c) {
+		return false
+	}
+	for _, hole := range rings[1:] {
+		if ringContains(hole, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains casts a ray east of c and counts the crossed edges, treating positions as planar
+func ringContains(ring [][]float64, c Coordinate) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		if len(ring[i]) < 2 || len(ring[j]) < 2 {
+			continue
+		}
+		lngI, latI := ring[i][0], ring[i][1]
+		lngJ, latJ := ring[j][0], ring[j][1]
+		if (latI > c.Lat) != (latJ > c.Lat) && c.Lng < (lngJ-lngI)*(c.Lat-latI)/(latJ-latI)+lngI {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ConvexHull returns the convex hull of coords counterclockwise, starting from the westernmost point and
+// without repeating it at the end (Andrew's monotone chain, treating coordinates as planar). Duplicate and
+// collinear points are left out, fewer than three distinct points are returned as is.
+func ConvexHull(coords []Coordinate) []Coordinate {
+	points := make([]Coordinate, len(coords))
+	copy(points, coords)
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Lng != points[j].Lng {
+			return points[i].Lng < points[j].Lng
+		}
+		return points[i].Lat < points[j].Lat
+	})
+
+	unique := points[:0]
+	for i, p := range points {
+		if i == 0 || p != points[i-1] {
+			unique = append(unique, p)
+		}
+	}
+	if len(unique) < 3 {
+		return unique
+	}
+
+	// cross is positive when o, a, b turn counterclockwise
+	cross := func(o, a, b Coordinate) float64 {
+		return (a.Lng-o.Lng)*(b.Lat-o.Lat) - (a.Lat-o.Lat)*(b.Lng-o.Lng)
+	}
+
+	hull := make([]Coordinate, 0, 2*len(unique))
+	for _, p := range unique {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(unique) - 2; i >= 0; i-- {
+		p := unique[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	return hull[:len(hull)-1]
+}
+
+// Centroid returns the average of coords, treating coordinates as planar, e.g. for points close together
+// away from the antimeridian. See SphericalCentroid otherwise. Returns the zero Coordinate for no coords.
+func Centroid(coords []Coordinate) Coordinate {
+	if len(coords) == 0 {
+		return Coordinate{}
+	}
+
+	var centroid Coordinate
+	for _, c := range coords {
+		centroid.Lat += c.Lat
+		centroid.Lng += c.Lng
+	}
+	centroid.Lat /= float64(len(coords))
+	centroid.Lng /= float64(len(coords))
+	return centroid
+}
+
+// SphericalCentroid returns the spherical mean of coords, the average of their unit vectors projected back to
+// the sphere. Unlike Centroid it handles the antimeridian, the centroid of 179 and -179 is 180 rather than 0.
+// Returns the zero Coordinate for no coords, or coords whose mean is undefined, e.g. two antipodal points.
+func SphericalCentroid(coords []Coordinate) Coordinate {
+	var x, y, z float64
+	for _, c := range coords {
+		lat, lng := radians(c.Lat), radians(c.Lng)
+		x += math.Cos(lat) * math.Cos(lng)
+		y += math.Cos(lat) * math.Sin(lng)
+		z += math.Sin(lat)
+	}
+
+	norm := math.Sqrt(x*x + y*y + z*z)
+	if norm < 1e-12 {
+		return Coordinate{}
+	}
+	return Coordinate{
+		Lat: degrees(math.Asin(z / norm)),
+		Lng: degrees(math.Atan2(y, x)),
+	}
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
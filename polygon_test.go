@@ -0,0 +1,143 @@
+package mapbox
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPolygonArea(t *testing.T) {
+	// a 1°x1° cell at the equator, exact area is R² * Δλ * (sin φ2 - sin φ1)
+	cell := BoundingBox{Min: Coordinate{Lat: 0, Lng: 0}, Max: Coordinate{Lat: 1, Lng: 1}}.Polygon()[0]
+	expected := earthRadius * earthRadius * radians(1) * math.Sin(radians(1))
+
+	if actual := PolygonAreaSquareMeters(cell); math.Abs(actual-expected) > 1 {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	// orientation doesn't matter
+	reversed := make([]Coordinate, len(cell))
+	for i := range cell {
+		reversed[len(cell)-1-i] = cell[i]
+	}
+	if actual := PolygonAreaSquareMeters(reversed); math.Abs(actual-expected) > 1 {
+		t.Errorf("expected %v for reversed ring, got %v", expected, actual)
+	}
+
+	// holes are subtracted
+	hole := BoundingBox{Min: Coordinate{Lat: 0, Lng: 0}, Max: Coordinate{Lat: 0.5, Lng: 1}}.Polygon()[0]
+	holeArea := earthRadius * earthRadius * radians(1) * math.Sin(radians(0.5))
+	if actual := PolygonArea([][]Coordinate{cell, hole}); math.Abs(actual-(expected-holeArea)) > 1 {
+		t.Errorf("expected %v, got %v", expected-holeArea, actual)
+	}
+
+	if actual := PolygonAreaSquareMeters(cell[:2]); actual != 0 {
+		t.Errorf("expected 0 for degenerate ring, got %v", actual)
+	}
+}
+
+func TestGeometryContains(t *testing.T) {
+	square := [][]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole := [][]float64{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}
+	island := [][]float64{{20, 20}, {21, 20}, {21, 21}, {20, 21}, {20, 20}}
+
+	polygon := &Geometry{Type: "Polygon", Polygon: [][][]float64{square, hole}}
+	multiPolygon := &Geometry{Type: "MultiPolygon", MultiPolygon: [][][][]float64{{square}, {island}}}
+
+	tests := []struct {
+		geometry *Geometry
+		c        Coordinate
+		expected bool
+	}{
+		{polygon, Coordinate{Lat: 2, Lng: 2}, true},
+		{polygon, Coordinate{Lat: 5, Lng: 5}, false},
+		{polygon, Coordinate{Lat: 11, Lng: 5}, false},
+		{multiPolygon, Coordinate{Lat: 20.5, Lng: 20.5}, true},
+		{multiPolygon, Coordinate{Lat: 5, Lng: 5}, true},
+		{multiPolygon, Coordinate{Lat: 15, Lng: 15}, false},
+	}
+	for _, test := range tests {
+		actual, err := test.geometry.Contains(test.c)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if actual != test.expected {
+			t.Errorf("%v in %v: expected %v, got %v", test.c, test.geometry.Type, test.expected, actual)
+		}
+	}
+
+	if _, err := (&Geometry{Type: "Point", Coordinates: []float64{0, 0}}).Contains(Coordinate{}); err == nil {
+		t.Errorf("expected an error for a Point geometry")
+	}
+}
+
+func TestConvexHull(t *testing.T) {
+	tests := []struct {
+		coords   []Coordinate
+		expected []Coordinate
+	}{
+		{
+			// square with an inner point, a duplicate and a collinear point on an edge
+			[]Coordinate{{Lat: 0, Lng: 0}, {Lat: 1, Lng: 1}, {Lat: 2, Lng: 0}, {Lat: 2, Lng: 2}, {Lat: 0, Lng: 2}, {Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}},
+			[]Coordinate{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}, {Lat: 2, Lng: 2}, {Lat: 2, Lng: 0}},
+		},
+		{
+			// all collinear
+			[]Coordinate{{Lat: 0, Lng: 0}, {Lat: 1, Lng: 1}, {Lat: 2, Lng: 2}},
+			[]Coordinate{{Lat: 0, Lng: 0}, {Lat: 2, Lng: 2}},
+		},
+		{[]Coordinate{{Lat: 1, Lng: 1}, {Lat: 1, Lng: 1}}, []Coordinate{{Lat: 1, Lng: 1}}},
+		{nil, []Coordinate{}},
+	}
+
+	for _, test := range tests {
+		hull := ConvexHull(test.coords)
+		if len(hull) != len(test.expected) {
+			t.Errorf("expected %v, got %v", test.expected, hull)
+			continue
+		}
+		for i := range hull {
+			if hull[i] != test.expected[i] {
+				t.Errorf("expected %v, got %v", test.expected, hull)
+				break
+			}
+		}
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	coords := []Coordinate{{Lat: 0, Lng: 0}, {Lat: 2, Lng: 0}, {Lat: 2, Lng: 2}, {Lat: 0, Lng: 2}}
+	if centroid := Centroid(coords); centroid != (Coordinate{Lat: 1, Lng: 1}) {
+		t.Errorf("expected 1,1, got %v", centroid)
+	}
+	if centroid := Centroid(nil); centroid != (Coordinate{}) {
+		t.Errorf("expected the zero coordinate, got %v", centroid)
+	}
+
+	tests := []struct {
+		coords   []Coordinate
+		expected Coordinate
+	}{
+		{[]Coordinate{{Lat: 10, Lng: 20}}, Coordinate{Lat: 10, Lng: 20}},
+		{[]Coordinate{{Lat: 0, Lng: 179}, {Lat: 0, Lng: -179}}, Coordinate{Lat: 0, Lng: 180}},
+		{[]Coordinate{{Lat: 0, Lng: -10}, {Lat: 0, Lng: 10}}, Coordinate{Lat: 0, Lng: 0}},
+		{[]Coordinate{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 180}}, Coordinate{}},
+	}
+	for _, test := range tests {
+		centroid := SphericalCentroid(test.coords)
+		if math.Abs(centroid.Lat-test.expected.Lat) > 1e-9 || math.Abs(math.Abs(centroid.Lng)-math.Abs(test.expected.Lng)) > 1e-9 {
+			t.Errorf("%v: expected %v, got %v", test.coords, test.expected, centroid)
+		}
+	}
+
+	features := Features{
+		{ID: "nowhere"},
+		{ID: "a", Geometry: &Geometry{Type: "Point", Coordinates: []float64{-117.3, 33}}},
+		{ID: "b", Geometry: &Geometry{Type: "Point", Coordinates: []float64{-117.3, 33.2}}},
+	}
+	if centroid, ok := features.Centroid(); !ok || math.Abs(centroid.Lat-33.1) > 1e-6 || math.Abs(centroid.Lng+117.3) > 1e-6 {
+		t.Errorf("unexpected features centroid %v, %v", centroid, ok)
+	}
+	if _, ok := (Features{{ID: "nowhere"}}).Centroid(); ok {
+		t.Errorf("expected no centroid without locations")
+	}
+}
@@ -0,0 +1,87 @@
+package mapbox
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Precision of the polyline and polyline6 geometries
+const (
+	PolylinePrecision  = 5
+	Polyline6Precision = 6
+)
+
+// DecodePolyline decodes an encoded polyline with the given precision (5 for polyline, 6 for polyline6),
+// see https://developers.google.com/maps/documentation/utilities/polylinealgorithm
+func DecodePolyline(encoded string, precision int) (Coordinates, error) {
+	factor := math.Pow10(precision)
+	coordinates := make(Coordinates, 0, len(encoded)/4)
+
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		var deltas [2]int64
+		for d := range deltas {
+			var result int64
+			var shift uint
+			for {
+				if i >= len(encoded) {
+					return nil, fmt.Errorf("invalid polyline, unexpected end at %v", i)
+				}
+				b := int64(encoded[i]) - 63
+				i++
+				if b < 0 || shift > 60 {
+					return nil, fmt.Errorf("invalid polyline character at %v", i-1)
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[d] = ^(result >> 1)
+			} else {
+				deltas[d] = result >> 1
+			}
+		}
+
+		lat += deltas[0]
+		lng += deltas[1]
+		coordinates = append(coordinates, Coordinate{
+			Lat: float64(lat) / factor,
+			Lng: float64(lng) / factor,
+		})
+	}
+
+	return coordinates, nil
+}
+
+// EncodePolyline encodes coordinates as a polyline with the given precision (5 for polyline, 6 for polyline6)
+func EncodePolyline(coordinates Coordinates, precision int) string {
+	factor := math.Pow10(precision)
+
+	var b strings.Builder
+	var previousLat, previousLng int64
+	for _, coordinate := range coordinates {
+		lat := int64(math.Round(coordinate.Lat * factor))
+		lng := int64(math.Round(coordinate.Lng * factor))
+		encodePolylineValue(&b, lat-previousLat)
+		encodePolylineValue(&b, lng-previousLng)
+		previousLat, previousLng = lat, lng
+	}
+
+	return b.String()
+}
+
+func encodePolylineValue(b *strings.Builder, value int64) {
+	value <<= 1
+	if value < 0 {
+		value = ^value
+	}
+	for value >= 0x20 {
+		b.WriteByte(byte((0x20 | (value & 0x1f)) + 63))
+		value >>= 5
+	}
+	b.WriteByte(byte(value + 63))
+}
@@ -0,0 +1,50 @@
+package mapbox
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPolyline(t *testing.T) {
+	// example from https://developers.google.com/maps/documentation/utilities/polylinealgorithm
+	encoded := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	expected := Coordinates{
+		{Lat: 38.5, Lng: -120.2},
+		{Lat: 40.7, Lng: -120.95},
+		{Lat: 43.252, Lng: -126.453},
+	}
+
+	decoded, err := DecodePolyline(encoded, PolylinePrecision)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertCoordinates(t, expected, decoded, 1e-5)
+
+	if actual := EncodePolyline(expected, PolylinePrecision); actual != encoded {
+		t.Errorf("expected %q, got %q", encoded, actual)
+	}
+
+	// polyline6 round trip
+	precise := Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.73381, Lng: -117.193443}}
+	decoded, err = DecodePolyline(EncodePolyline(precise, Polyline6Precision), Polyline6Precision)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertCoordinates(t, precise, decoded, 1e-6)
+
+	if _, err := DecodePolyline("_p~iF~ps|U_", PolylinePrecision); err == nil {
+		t.Errorf("expected error for truncated polyline")
+	}
+}
+
+func assertCoordinates(t *testing.T, expected, actual Coordinates, tolerance float64) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if math.Abs(expected[i].Lat-actual[i].Lat) > tolerance || math.Abs(expected[i].Lng-actual[i].Lng) > tolerance {
+			t.Errorf("coordinate %v: expected %v, got %v", i, expected[i], actual[i])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package mapbox
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Recorder is an HTTPClient that records request/response pairs to dir and replays them on later runs,
+// skipping the network, e.g. for deterministic tests of code using the client. Requests are matched on
+// their method, redacted URL and body, the access token is never written to the fixtures.
+type Recorder struct {
+	dir    string
+	client HTTPClient
+}
+
+type recording struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Body       string      `json:"body,omitempty"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Response   string      `json:"response"`
+}
+
+// NewRecorder returns a Recorder storing fixtures in dir and sending unrecorded requests with client
+func NewRecorder(dir string, client HTTPClient) *Recorder {
+	return &Recorder{dir: dir, client: client}
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body. %w", err)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	rec := recording{Method: req.Method, URL: RedactURL(req.URL), Body: string(body)}
+	path := r.path(rec)
+
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to read recording %v. %w", path, err)
+		}
+		return rec.response(req), nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read recording %v. %w", path, err)
+	}
+
+	response, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body. %w", err)
+	}
+
+	rec.StatusCode = response.StatusCode
+	rec.Header = response.Header
+	rec.Response = string(responseBody)
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recording. %w", err)
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create recordings dir. %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write recording %v. %w", path, err)
+	}
+
+	return rec.response(req), nil
+}
+
+// path returns the fixture file of the request
+func (r *Recorder) path(rec recording) string {
+	hash := sha256.Sum256([]byte(rec.Method + " " + rec.URL + "\n" + rec.Body))
+	return filepath.Join(r.dir, hex.EncodeToString(hash[:8])+".json")
+}
+
+func (rec recording) response(req *http.Request) *http.Response {
+	header := rec.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(rec.Response)),
+		ContentLength: int64(len(rec.Response)),
+		Request:       req,
+	}
+}
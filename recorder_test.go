@@ -0,0 +1,63 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mapbox-recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	calls := 0
+	newClient := func() *Client {
+		c, err := NewClient(&MapboxConfig{
+			APIKey:      "secret-token",
+			RecorderDir: dir,
+			Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"X-Rate-Limit-Limit": []string{"600"}},
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"place.1","text":"Carlsbad"}]}`)),
+				}, nil
+			})},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return c
+	}
+
+	req := &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}
+	for i := 0; i < 2; i++ {
+		response, err := newClient().ForwardGeocode(context.Background(), req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(response.Features) != 1 || response.Features[0].Text != "Carlsbad" {
+			t.Errorf("unexpected response %+v", response)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the second request to be replayed, got %v calls", calls)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 recording, got %v", files)
+	}
+	data, _ := ioutil.ReadFile(files[0])
+	if strings.Contains(string(data), "secret-token") || !strings.Contains(string(data), "access_token=***") {
+		t.Errorf("expected the token to be redacted, got %s", data)
+	}
+}
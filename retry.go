@@ -0,0 +1,121 @@
+package mapbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 10 * time.Second
+)
+
+// retryPolicy retries transient failures with exponential backoff and full jitter,
+// see https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type retryPolicy struct {
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	// statuses replaces the retryable status codes, nil keeps the defaults
+	statuses map[int]bool
+	// int63n returns a random number in [0, n), defaults to math/rand
+	int63n func(n int64) int64
+}
+
+// retryable reports whether a response with statusCode is worth retrying
+func (p retryPolicy) retryable(statusCode int) bool {
+	if p.statuses != nil {
+		return p.statuses[statusCode]
+	}
+	switch statusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryable reports whether err is a transient failure worth retrying: a network timeout, a refused or reset
+// connection, a temporary DNS failure, or a Mapbox 500, 502, 503 or 504. Context cancellation and deadlines are
+// not retryable, nor are the other errors, e.g. an invalid request or token.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var mapboxErr MapboxError
+	if errors.As(err, &mapboxErr) {
+		return retryPolicy{}.retryable(mapboxErr.StatusCode)
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	// a connection closed by the server, e.g. a reused keep-alive connection, reads an unexpected EOF
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// delay returns the randomized delay before retry attempt (0 based), between 0 and min(maxBackoff, backoff * 2^attempt)
+func (p retryPolicy) delay(attempt int) time.Duration {
+	ceiling := p.maxBackoff
+	if attempt < 32 {
+		if exp := p.backoff << uint(attempt); exp > 0 && exp < ceiling {
+			ceiling = exp
+		}
+	}
+
+	int63n := p.int63n
+	if int63n == nil {
+		int63n = rand.Int63n
+	}
+	return time.Duration(int63n(int64(ceiling) + 1))
+}
+
+// retryAfter returns the wait of the Retry-After header, in seconds or an HTTP date, false when there is none
+// or it is malformed. A date in the past is no wait.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := date.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
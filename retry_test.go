@@ -0,0 +1,246 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := retryPolicy{
+		maxRetries: 10,
+		backoff:    100 * time.Millisecond,
+		maxBackoff: time.Second,
+		int63n:     rand.New(rand.NewSource(1)).Int63n,
+	}
+
+	for attempt := 0; attempt < 40; attempt++ {
+		ceiling := policy.maxBackoff
+		if attempt < 4 {
+			ceiling = policy.backoff << uint(attempt)
+		}
+
+		var distinct = map[time.Duration]bool{}
+		for i := 0; i < 20; i++ {
+			delay := policy.delay(attempt)
+			if delay < 0 || delay > ceiling {
+				t.Fatalf("attempt %v: delay %v out of [0, %v]", attempt, delay, ceiling)
+			}
+			distinct[delay] = true
+		}
+		if len(distinct) < 2 {
+			t.Errorf("attempt %v: expected jittered delays, got %v", attempt, distinct)
+		}
+	}
+}
+
+func TestClientRetriesServiceUnavailable(t *testing.T) {
+	client, requests := mockClient(
+		&http.Response{StatusCode: http.StatusServiceUnavailable, Body: ioutil.NopCloser(bytes.NewBufferString(`{"message":"maintenance"}`))},
+		&http.Response{StatusCode: http.StatusServiceUnavailable, Body: ioutil.NopCloser(bytes.NewBufferString(`{"message":"maintenance"}`))},
+		&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`))},
+	)
+	client.retry = retryPolicy{maxRetries: 2, backoff: time.Millisecond, maxBackoff: time.Millisecond}
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+
+	_, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"})
+	if err != nil {
+		t.Fatalf("expected retries to succeed, got %v", err)
+	}
+}
+
+func TestRetryableStatuses(t *testing.T) {
+	client, err := NewClient(&MapboxConfig{APIKey: "test", MaxRetries: 1, RetryableStatuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for code, expected := range map[int]bool{429: true, 503: true, 500: false, 502: false} {
+		if retryable := client.retry.retryable(code); retryable != expected {
+			t.Errorf("%v: expected retryable %v, got %v", code, expected, retryable)
+		}
+	}
+
+	client, _ = NewClient(&MapboxConfig{APIKey: "test"})
+	if client.retry.retryable(http.StatusTooManyRequests) || !client.retry.retryable(http.StatusGatewayTimeout) {
+		t.Errorf("expected the default retryable statuses")
+	}
+
+	for _, code := range []int{200, 302, 600} {
+		if _, err := NewClient(&MapboxConfig{APIKey: "test", RetryableStatuses: []int{code}}); err == nil {
+			t.Errorf("expected status %v to be rejected", code)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	opError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://api.mapbox.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}}
+	}
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{nil, false},
+		{&url.Error{Op: "Get", URL: "https://api.mapbox.com", Err: timeoutError{}}, true},
+		{opError(syscall.ECONNREFUSED), true},
+		{opError(syscall.ECONNRESET), true},
+		{fmt.Errorf("failed to read response. %w", io.ErrUnexpectedEOF), true},
+		{errors.New("invalid character"), false},
+		{&net.DNSError{Err: "server misbehaving", Name: "api.mapbox.com", IsTemporary: true}, true},
+		{&net.DNSError{Err: "no such host", Name: "api.mapbox.com", IsNotFound: true}, false},
+		{MapboxError{StatusCode: http.StatusServiceUnavailable}, true},
+		{MapboxError{StatusCode: http.StatusUnauthorized}, false},
+		{&url.Error{Op: "Get", URL: "https://api.mapbox.com", Err: context.Canceled}, false},
+		{&url.Error{Op: "Get", URL: "https://api.mapbox.com", Err: context.DeadlineExceeded}, false},
+		{ErrCircuitOpen, false},
+	}
+
+	for _, test := range tests {
+		if retryable := IsRetryable(test.err); retryable != test.retryable {
+			t.Errorf("%v: expected retryable %v, got %v", test.err, test.retryable, retryable)
+		}
+	}
+}
+
+func TestClientRetriesNetworkErrors(t *testing.T) {
+	var calls int
+	client, err := NewClient(&MapboxConfig{
+		APIKey:       "test",
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`)),
+			}, nil
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %v", calls)
+	}
+
+	// a canceled context isn't retried
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err = NewClient(&MapboxConfig{
+		APIKey:       "test",
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			cancel()
+			return nil, context.Canceled
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.ForwardGeocode(ctx, &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %v", calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		wait  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Fri, 01 Mar 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Fri, 01 Mar 2024 11:59:00 GMT", 0, true},
+	}
+
+	for _, test := range tests {
+		header := http.Header{}
+		if test.value != "" {
+			header.Set("Retry-After", test.value)
+		}
+		if wait, ok := retryAfter(header, now); wait != test.wait || ok != test.ok {
+			t.Errorf("%q: expected %v %v, got %v %v", test.value, test.wait, test.ok, wait, ok)
+		}
+	}
+}
+
+func TestClientHonorsRetryAfter(t *testing.T) {
+	unavailable := func(retryAfter string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Retry-After": []string{retryAfter}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"maintenance"}`)),
+		}
+	}
+	client, requests := mockClient(
+		unavailable("1"),
+		&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`))},
+	)
+	client.retry = retryPolicy{maxRetries: 2, backoff: time.Millisecond, maxBackoff: 2 * time.Second}
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+
+	start := time.Now()
+	if _, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait the Retry-After second, waited %v", elapsed)
+	}
+
+	// a Retry-After beyond the longest backoff isn't waited for
+	client, requests = mockClient(unavailable("60"))
+	client.retry = retryPolicy{maxRetries: 2, backoff: time.Millisecond, maxBackoff: 2 * time.Second}
+	go func() {
+		for range requests {
+		}
+	}()
+	defer close(requests)
+
+	_, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"})
+	var mapboxErr MapboxError
+	if !errors.As(err, &mapboxErr) || mapboxErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the 503, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package mapbox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// ReverseGeocoder reverse geocodes coordinates, implemented by the Client and by local geocoders,
+// e.g. a bundled coarse country/region lookup for offline use
+type ReverseGeocoder interface {
+	ReverseGeocode(ctx context.Context, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error)
+}
+
+var _ ReverseGeocoder = (*Client)(nil)
+
+// FallbackReverseGeocoder tries the Primary geocoder, usually the Client, and answers from the Local one
+// when Mapbox is unavailable: network errors, an open circuit breaker, rate limiting and 5xx errors.
+// Other errors, e.g. an invalid request or token, are returned as is.
+type FallbackReverseGeocoder struct {
+	Primary ReverseGeocoder
+	Local   ReverseGeocoder
+
+	// Offline skips the Primary geocoder, e.g. when the user opted out of sending locations to Mapbox
+	Offline bool
+}
+
+var _ ReverseGeocoder = (*FallbackReverseGeocoder)(nil)
+
+func (g *FallbackReverseGeocoder) ReverseGeocode(ctx context.Context, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error) {
+	if g.Offline {
+		return g.Local.ReverseGeocode(ctx, req)
+	}
+
+	response, err := g.Primary.ReverseGeocode(ctx, req)
+	if err == nil || ctx.Err() != nil || !unavailable(err) {
+		return response, err
+	}
+	return g.Local.ReverseGeocode(ctx, req)
+}
+
+// unavailable reports whether err means Mapbox couldn't be reached or couldn't answer, rather than rejected the request
+func unavailable(err error) bool {
+	var mapboxErr MapboxError
+	if errors.As(err, &mapboxErr) {
+		return mapboxErr.StatusCode == http.StatusTooManyRequests || mapboxErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.Is(err, ErrCircuitOpen) || errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
@@ -0,0 +1,63 @@
+package mapbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+type reverseGeocoderFunc func(ctx context.Context, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error)
+
+func (f reverseGeocoderFunc) ReverseGeocode(ctx context.Context, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error) {
+	return f(ctx, req)
+}
+
+func TestFallbackReverseGeocoder(t *testing.T) {
+	local := reverseGeocoderFunc(func(ctx context.Context, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error) {
+		return &ReverseGeocodeResponse{Features: Features{{ID: "country.local"}}}, nil
+	})
+	failing := func(err error) ReverseGeocoder {
+		return reverseGeocoderFunc(func(ctx context.Context, req *ReverseGeocodeRequest) (*ReverseGeocodeResponse, error) {
+			return nil, err
+		})
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		fallback bool
+	}{
+		{"network", fmt.Errorf("failed to send request. %w", &url.Error{Op: "Get", URL: "https://api.mapbox.com", Err: errors.New("no route to host")}), true},
+		{"circuit open", ErrCircuitOpen, true},
+		{"rate limited", NewMapboxError(429, "Rate limiting geocoding requests"), true},
+		{"server error", NewMapboxError(503, "unavailable"), true},
+		{"invalid token", NewMapboxError(401, "Not Authorized - Invalid Token"), false},
+		{"invalid request", errors.New("reverse geocoding requires coordinates"), false},
+	}
+
+	req := &ReverseGeocodeRequest{Endpoint: EndpointPlaces, Coordinates: Coordinates{{Lat: 33.12, Lng: -117.3}}}
+	for _, test := range tests {
+		geocoder := &FallbackReverseGeocoder{Primary: failing(test.err), Local: local}
+		response, err := geocoder.ReverseGeocode(context.Background(), req)
+		if test.fallback && (err != nil || response.Features[0].ID != "country.local") {
+			t.Errorf("%v: expected the local result, got %+v, %v", test.name, response, err)
+		}
+		if !test.fallback && (err == nil || err.Error() != test.err.Error()) {
+			t.Errorf("%v: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	offline := &FallbackReverseGeocoder{Primary: failing(errors.New("unexpected call")), Local: local, Offline: true}
+	if response, err := offline.ReverseGeocode(context.Background(), req); err != nil || response.Features[0].ID != "country.local" {
+		t.Errorf("expected the local result offline, got %+v, %v", response, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceled := &FallbackReverseGeocoder{Primary: failing(context.Canceled), Local: local}
+	if _, err := canceled.ReverseGeocode(ctx, req); err != context.Canceled {
+		t.Errorf("expected the canceled request not to fall back, got %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+package mapbox
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	searchBoxPath = "search/searchbox"
+)
+
+//////////////////////////////////////////////////////////////////
+
+type SearchBoxForwardRequest struct {
+	// required
+	SearchText string
+
+	// optional
+	BBox          BoundingBox
+	Country       string
+	Language      string
+	Limit         int // 1-10
+	Proximity     Coordinate
+	Types         Types
+	POICategories Categories // canonical category IDs, e.g. CategoryCoffee, CategoryRestaurant
+	// CoordinatePrecision overrides the client CoordinatePrecision for Proximity with 1-15 decimals, -1 sends full precision
+	CoordinatePrecision int
+
+	// Optional travel time estimates, ETATypeNavigation requires a NavigationProfile and ranks by ETA from the
+	// Origin, or the Proximity when unset
+	ETAType           ETAType
+	NavigationProfile Profile // ProfileDriving, ProfileWalking or ProfileCycling
+	Origin            Coordinate
+}
+
+type SearchBoxForwardResponse struct {
+	Type        string   `json:"type"`
+	Features    Features `json:"features"`
+	Attribution string   `json:"attribution"`
+}
+
+// SearchBoxContext is the hierarchy of a Search Box feature, found in its Properties
+type SearchBoxContext struct {
+	Country      *SearchBoxContextComponent `json:"country,omitempty"`
+	Region       *SearchBoxContextComponent `json:"region,omitempty"`
+	Postcode     *SearchBoxContextComponent `json:"postcode,omitempty"`
+	District     *SearchBoxContextComponent `json:"district,omitempty"`
+	Place        *SearchBoxContextComponent `json:"place,omitempty"`
+	Locality     *SearchBoxContextComponent `json:"locality,omitempty"`
+	Neighborhood *SearchBoxContextComponent `json:"neighborhood,omitempty"`
+	Street       *SearchBoxContextComponent `json:"street,omitempty"`
+	Address      *SearchBoxContextComponent `json:"address,omitempty"`
+	// SecondaryAddress is the unit within the address, e.g. "Apt 4"
+	SecondaryAddress *SearchBoxContextComponent `json:"secondary_address,omitempty"`
+}
+
+// SearchBoxContextComponent is a level of a SearchBoxContext, only the fields relevant to the level are set
+type SearchBoxContextComponent struct {
+	MapboxID          string `json:"mapbox_id,omitempty"`
+	Name              string `json:"name"`
+	WikidataID        string `json:"wikidata_id,omitempty"`
+	CountryCode       string `json:"country_code,omitempty"`
+	CountryCodeAlpha3 string `json:"country_code_alpha_3,omitempty"`
+	RegionCode        string `json:"region_code,omitempty"`
+	RegionCodeFull    string `json:"region_code_full,omitempty"`
+	AddressNumber     string `json:"address_number,omitempty"`
+	StreetName        string `json:"street_name,omitempty"`
+}
+
+// component returns the level of type t, nil when absent
+func (c *SearchBoxContext) component(t Type) *SearchBoxContextComponent {
+	switch t {
+	case TypeCountry:
+		return c.Country
+	case TypeRegion:
+		return c.Region
+	case TypePostcode:
+		return c.Postcode
+	case TypeDistrict:
+		return c.District
+	case TypePlace:
+		return c.Place
+	case TypeLocality:
+		return c.Locality
+	case TypeNeighborhood:
+		return c.Neighborhood
+	case TypeAddress:
+		return c.Address
+	case TypeSecondaryAddress:
+		return c.SecondaryAddress
+	case TypeStreet:
+		return c.Street
+	default:
+		return nil
+	}
+}
+
+//////////////////////////////////////////////////////////////////
+
+func (r *SearchBoxForwardRequest) validate() error {
+	if r.SearchText == "" {
+		return fmt.Errorf("missing search text")
+	}
+	if r.Limit < 0 || r.Limit > 10 {
+		return fmt.Errorf("search box limit must be between 1 and 10, got %v", r.Limit)
+	}
+	if r.BBox.Min.Lat != 0 && r.BBox.Min.Lng != 0 {
+		if err := r.BBox.validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateLanguage(r.Language); err != nil {
+		return err
+	}
+	if err := r.validateETA(); err != nil {
+		return err
+	}
+	return validatePrecision(r.CoordinatePrecision)
+}
+
+// CacheKey returns the canonical key of the request, see ForwardGeocodeRequest.CacheKey
+func (r *SearchBoxForwardRequest) CacheKey() string {
+	req := *r
+	return cacheKey(searchBoxForwardQuery(&Client{}, &req))
+}
+
+// validateETA checks the ETA parameters, which Search Box requires together
+func (r *SearchBoxForwardRequest) validateETA() error {
+	if r.ETAType == "" {
+		if r.NavigationProfile != "" || r.Origin.Lat != 0 {
+			return fmt.Errorf("navigation profile and origin require the %v eta type", ETATypeNavigation)
+		}
+		return nil
+	}
+	if r.ETAType != ETATypeNavigation {
+		return fmt.Errorf("invalid eta type %q, expected %v", r.ETAType, ETATypeNavigation)
+	}
+	switch r.NavigationProfile {
+	case ProfileDriving, ProfileWalking, ProfileCycling:
+	default:
+		return fmt.Errorf("%v eta type requires a driving, walking or cycling navigation profile, got %q", r.ETAType, r.NavigationProfile)
+	}
+	if r.Origin.Lat == 0 && r.Proximity.Lat == 0 {
+		return fmt.Errorf("%v eta type requires an origin or a proximity", r.ETAType)
+	}
+	return nil
+}
+
+// https://docs.mapbox.com/api/search/search-box/#search-request
+func searchBoxForwardQuery(client *Client, req *SearchBoxForwardRequest) (string, url.Values, error) {
+	if err := req.validate(); err != nil {
+		return "", nil, err
+	}
+
+	relPath := fmt.Sprintf("%v/%v/forward", searchBoxPath, v1)
+
+	query := url.Values{}
+	query.Set("q", req.SearchText)
+	if req.BBox.Min.Lat != 0 && req.BBox.Min.Lng != 0 {
+		query.Set("bbox", req.BBox.Normalize().query())
+	}
+	if req.Country != "" {
+		query.Set("country", req.Country)
+	}
+	if req.Language != "" {
+		query.Set("language", req.Language)
+	}
+	if limit := client.limit(req.Limit); limit != 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if req.Proximity.Lat != 0 {
+		query.Set("proximity", req.Proximity.format(client.precision(req.CoordinatePrecision)))
+	}
+	if len(req.Types) != 0 {
+		query.Set("types", req.Types.query())
+	}
+	if len(req.POICategories) != 0 {
+		query.Set("poi_category", req.POICategories.query())
+	}
+	if req.ETAType != "" {
+		query.Set("eta_type", string(req.ETAType))
+		// Search Box names the profiles without the mapbox/ prefix
+		query.Set("navigation_profile", strings.TrimPrefix(string(req.NavigationProfile), "mapbox/"))
+		if req.Origin.Lat != 0 {
+			query.Set("origin", req.Origin.format(client.precision(req.CoordinatePrecision)))
+		}
+	}
+
+	return relPath, query, nil
+}
+
+func searchBoxForward(ctx context.Context, client *Client, req *SearchBoxForwardRequest) (*SearchBoxForwardResponse, error) {
+	relPath, query, err := searchBoxForwardQuery(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	apiResponse, err := client.get(ctx, relPath, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response SearchBoxForwardResponse
+	decodeErr := client.handleResponse(apiResponse, &response, SearchBoxRateLimit)
+	if decodeErr != nil && !isPartialDecode(decodeErr) {
+		return nil, decodeErr
+	}
+	if err := client.checkResponseType(response.Type, featureCollection); err != nil {
+		return nil, err
+	}
+
+	if req.Proximity.Lat != 0 {
+		response.Features.WithDistancesFrom(req.Proximity)
+	}
+
+	return &response, decodeErr
+}
+
+//////////////////////////////////////////////////////////////////
+
+// AddressLine returns the street line of the address, e.g. "6965 El Camino Real", from the Search Box context
+// when present and the address property otherwise
+func (p *Properties) AddressLine() string {
+	if p == nil {
+		return ""
+	}
+	if p.Context != nil && p.Context.Address != nil {
+		address := p.Context.Address
+		if address.StreetName != "" {
+			return strings.TrimSpace(address.AddressNumber + " " + address.StreetName)
+		}
+		if address.Name != "" {
+			return address.Name
+		}
+	}
+	if p.Context != nil && p.Context.Street != nil && p.Context.Street.Name != "" && p.Address == "" {
+		return p.Context.Street.Name
+	}
+	return p.Address
+}
+
+// CityStateZip returns the second address line, e.g. "Carlsbad, CA 92009", from the Search Box context.
+// The region code is preferred over the region name, missing parts are left out.
+func (p *Properties) CityStateZip() string {
+	if p == nil || p.Context == nil {
+		return ""
+	}
+
+	var city, state, zip string
+	if p.Context.Place != nil {
+		city = p.Context.Place.Name
+	} else if p.Context.Locality != nil {
+		city = p.Context.Locality.Name
+	}
+	if region := p.Context.Region; region != nil {
+		state = region.RegionCode
+		if state == "" {
+			state = region.Name
+		}
+	}
+	if p.Context.Postcode != nil {
+		zip = p.Context.Postcode.Name
+	}
+
+	stateZip := strings.TrimSpace(state + " " + zip)
+	switch {
+	case city != "" && stateZip != "":
+		return city + ", " + stateZip
+	case city != "":
+		return city
+	default:
+		return stateZip
+	}
+}
+
+type AddressStyle int
+
+const (
+	// AddressStyleUS formats as "123 Main St, Springfield, IL 62701, United States"
+	AddressStyleUS AddressStyle = iota
+	// AddressStyleEU formats as "Hauptstraße 12, 10115 Berlin, Germany"
+	AddressStyleEU
+)
+
+// FormatAddress assembles the full address from the Search Box context following style, falling back
+// to FullAddress when the context lacks the street or city
+func (p *Properties) FormatAddress(style AddressStyle) string {
+	if p == nil {
+		return ""
+	}
+	if p.Context == nil {
+		return p.FullAddress
+	}
+
+	var city string
+	if p.Context.Place != nil {
+		city = p.Context.Place.Name
+	} else if p.Context.Locality != nil {
+		city = p.Context.Locality.Name
+	}
+	street := p.AddressLine()
+	if street == "" || city == "" {
+		return p.FullAddress
+	}
+
+	var zip, country string
+	if p.Context.Postcode != nil {
+		zip = p.Context.Postcode.Name
+	}
+	if p.Context.Country != nil {
+		country = p.Context.Country.Name
+	}
+
+	var parts []string
+	switch style {
+	case AddressStyleEU:
+		if address := p.Context.Address; address != nil && address.StreetName != "" && address.AddressNumber != "" {
+			street = address.StreetName + " " + address.AddressNumber
+		}
+		parts = []string{street, strings.TrimSpace(zip + " " + city), country}
+	default:
+		parts = []string{street, p.CityStateZip(), country}
+	}
+
+	lines := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			lines = append(lines, part)
+		}
+	}
+	return strings.Join(lines, ", ")
+}
@@ -0,0 +1,191 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+const searchBoxForwardJSON = `{
+	"type": "FeatureCollection",
+	"features": [{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [-117.3101, 33.1227]},
+		"properties": {
+			"name": "Starbucks",
+			"mapbox_id": "dXJuOm1ieHBvaTo0ZTg2",
+			"feature_type": "poi",
+			"address": "6965 El Camino Real",
+			"full_address": "6965 El Camino Real, Carlsbad, California 92009, United States",
+			"place_formatted": "Carlsbad, California 92009, United States",
+			"poi_category": ["coffee", "cafe"],
+			"poi_category_ids": ["coffee", "cafe"],
+			"brand": ["Starbucks"],
+			"brand_id": ["starbucks"],
+			"external_ids": {"foursquare": "4b0586"},
+			"maki": "cafe",
+			"context": {
+				"country": {"name": "United States", "country_code": "US", "country_code_alpha_3": "USA"},
+				"region": {"name": "California", "region_code": "CA", "region_code_full": "US-CA"},
+				"postcode": {"name": "92009"},
+				"place": {"name": "Carlsbad"}
+			}
+		}
+	}],
+	"attribution": "© 2024 Mapbox and its suppliers."
+}`
+
+func TestSearchBoxForward(t *testing.T) {
+	client, requests := mockClient(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(searchBoxForwardJSON)),
+	})
+	client.apiKey = "token"
+
+	var httpReq *http.Request
+	done := make(chan struct{})
+	go func() {
+		httpReq = <-requests
+		close(done)
+	}()
+
+	response, err := client.SearchBoxForward(context.Background(), &SearchBoxForwardRequest{
+		SearchText:    "starbucks",
+		Proximity:     Coordinate{Lat: 33.1227, Lng: -117.3101},
+		Limit:         5,
+		Types:         Types{TypePOI, TypeSecondaryAddress},
+		POICategories: Categories{CategoryCoffee, CategoryCafe},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	<-done
+
+	expected := "https://api.mapbox.com/search/searchbox/v1/forward?access_token=token&limit=5&poi_category=coffee%2Ccafe&proximity=-117.3101%2C33.1227&q=starbucks&types=poi%2Csecondary_address"
+	if httpReq.URL.String() != expected {
+		t.Errorf("expected:\n%s, got:\n%s", expected, httpReq.URL.String())
+	}
+
+	if len(response.Features) != 1 {
+		t.Fatalf("expected 1 feature, got %v", len(response.Features))
+	}
+	properties := response.Features[0].Properties
+	if properties.Name != "Starbucks" || properties.FeatureType != "poi" || len(properties.POICategory) != 2 || properties.ExternalIDs["foursquare"] != "4b0586" {
+		t.Errorf("unexpected properties %+v", properties)
+	}
+	if properties.Context == nil || properties.Context.Region.RegionCode != "CA" || properties.Context.Place.Name != "Carlsbad" {
+		t.Errorf("unexpected context %+v", properties.Context)
+	}
+	if coordinate, ok := response.Features[0].Coordinate(); !ok || coordinate.Lng != -117.3101 {
+		t.Errorf("unexpected coordinate %v", coordinate)
+	}
+
+	if _, err := client.SearchBoxForwardURL(context.Background(), &SearchBoxForwardRequest{SearchText: "starbucks", Limit: 11}); err == nil {
+		t.Errorf("expected limit validation error")
+	}
+}
+
+func TestSearchBoxForwardETA(t *testing.T) {
+	client, _ := NewClient(&MapboxConfig{APIKey: "test"})
+	origin := Coordinate{Lat: 33.1227, Lng: -117.3101}
+
+	u, err := client.SearchBoxForwardURL(context.Background(), &SearchBoxForwardRequest{
+		SearchText:        "coffee",
+		ETAType:           ETATypeNavigation,
+		NavigationProfile: ProfileWalking,
+		Origin:            origin,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	query := u.Query()
+	if query.Get("eta_type") != "navigation" || query.Get("navigation_profile") != "walking" || query.Get("origin") != "-117.3101,33.1227" {
+		t.Errorf("unexpected eta parameters %v", query)
+	}
+
+	for _, req := range []*SearchBoxForwardRequest{
+		{SearchText: "coffee", ETAType: ETATypeNavigation, Origin: origin},
+		{SearchText: "coffee", ETAType: ETATypeNavigation, NavigationProfile: ProfileDrivingTraffic, Origin: origin},
+		{SearchText: "coffee", ETAType: ETATypeNavigation, NavigationProfile: ProfileDriving},
+		{SearchText: "coffee", ETAType: "fastest", NavigationProfile: ProfileDriving, Origin: origin},
+		{SearchText: "coffee", NavigationProfile: ProfileDriving},
+	} {
+		if err := req.validate(); err == nil {
+			t.Errorf("expected an eta validation error for %+v", req)
+		}
+	}
+}
+
+func TestPropertiesAddressLines(t *testing.T) {
+	tests := []struct {
+		properties   *Properties
+		addressLine  string
+		cityStateZip string
+	}{
+		{
+			&Properties{
+				Address: "6965 El Camino Real",
+				Context: &SearchBoxContext{
+					Address:  &SearchBoxContextComponent{Name: "6965 El Camino Real", AddressNumber: "6965", StreetName: "El Camino Real"},
+					Place:    &SearchBoxContextComponent{Name: "Carlsbad"},
+					Region:   &SearchBoxContextComponent{Name: "California", RegionCode: "CA"},
+					Postcode: &SearchBoxContextComponent{Name: "92009"},
+				},
+			},
+			"6965 El Camino Real", "Carlsbad, CA 92009",
+		},
+		{
+			&Properties{Context: &SearchBoxContext{
+				Street:   &SearchBoxContextComponent{Name: "Main Street"},
+				Locality: &SearchBoxContextComponent{Name: "Springfield"},
+				Region:   &SearchBoxContextComponent{Name: "Oregon"},
+			}},
+			"Main Street", "Springfield, Oregon",
+		},
+		{&Properties{Address: "12 High St", Context: &SearchBoxContext{Postcode: &SearchBoxContextComponent{Name: "SW1A"}}}, "12 High St", "SW1A"},
+		{&Properties{}, "", ""},
+		{nil, "", ""},
+	}
+
+	for _, test := range tests {
+		if line := test.properties.AddressLine(); line != test.addressLine {
+			t.Errorf("expected address line %q, got %q", test.addressLine, line)
+		}
+		if line := test.properties.CityStateZip(); line != test.cityStateZip {
+			t.Errorf("expected city line %q, got %q", test.cityStateZip, line)
+		}
+	}
+}
+
+func TestPropertiesFormatAddress(t *testing.T) {
+	us := &Properties{Context: &SearchBoxContext{
+		Address:  &SearchBoxContextComponent{AddressNumber: "123", StreetName: "Main St"},
+		Place:    &SearchBoxContextComponent{Name: "Springfield"},
+		Region:   &SearchBoxContextComponent{Name: "Illinois", RegionCode: "IL"},
+		Postcode: &SearchBoxContextComponent{Name: "62701"},
+		Country:  &SearchBoxContextComponent{Name: "United States"},
+	}}
+	if address := us.FormatAddress(AddressStyleUS); address != "123 Main St, Springfield, IL 62701, United States" {
+		t.Errorf("unexpected US address %q", address)
+	}
+
+	eu := &Properties{Context: &SearchBoxContext{
+		Address:  &SearchBoxContextComponent{AddressNumber: "12", StreetName: "Hauptstraße"},
+		Place:    &SearchBoxContextComponent{Name: "Berlin"},
+		Postcode: &SearchBoxContextComponent{Name: "10115"},
+		Country:  &SearchBoxContextComponent{Name: "Germany"},
+	}}
+	if address := eu.FormatAddress(AddressStyleEU); address != "Hauptstraße 12, 10115 Berlin, Germany" {
+		t.Errorf("unexpected EU address %q", address)
+	}
+
+	incomplete := &Properties{
+		FullAddress: "Main Street, Springfield",
+		Context:     &SearchBoxContext{Place: &SearchBoxContextComponent{Name: "Springfield"}},
+	}
+	if address := incomplete.FormatAddress(AddressStyleUS); address != "Main Street, Springfield" {
+		t.Errorf("expected the full address fallback, got %q", address)
+	}
+}
@@ -0,0 +1,90 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// flightGroup shares one HTTP call between concurrent identical requests, in the spirit of
+// golang.org/x/sync/singleflight without the dependency
+type flightGroup struct {
+	mutex sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done     chan struct{} // closed once the call finished
+	response *http.Response
+	body     []byte
+	err      error
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flightCall)}
+}
+
+// do calls fn once for all the concurrent callers of key, each caller gets its own copy of the response
+// to read and close. The error of fn is returned to every caller, except the context errors of the caller
+// running fn: the others, whose ctx is still live, call again. A caller stops waiting when its ctx is done.
+// Only the call of fn is sent, so the observer sees the one request with the context of the caller running it.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*http.Response, error)) (*http.Response, error) {
+	for {
+		g.mutex.Lock()
+		call, ok := g.calls[key]
+		if !ok {
+			call = &flightCall{done: make(chan struct{})}
+			g.calls[key] = call
+		}
+		g.mutex.Unlock()
+
+		if !ok {
+			call.response, call.body, call.err = readResponse(fn())
+
+			g.mutex.Lock()
+			delete(g.calls, key)
+			g.mutex.Unlock()
+			close(call.done)
+			return call.copy()
+		}
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		canceled := errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)
+		if !canceled || ctx.Err() != nil {
+			return call.copy()
+		}
+	}
+}
+
+// copy returns the error of the call or a copy of its response
+func (call *flightCall) copy() (*http.Response, error) {
+	if call.err != nil {
+		return nil, call.err
+	}
+	response := *call.response
+	response.Header = call.response.Header.Clone()
+	response.Body = ioutil.NopCloser(bytes.NewReader(call.body))
+	return &response, nil
+}
+
+// readResponse reads and closes the body of the response so it can be shared
+func readResponse(response *http.Response, err error) (*http.Response, []byte, error) {
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read body. %w", err)
+	}
+	return response, body, nil
+}
@@ -0,0 +1,140 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientSingleflight(t *testing.T) {
+	var calls int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	client, err := NewClient(&MapboxConfig{
+		APIKey:       "test",
+		Singleflight: true,
+		Client: &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			started <- struct{}{}
+			<-release
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[{"id":"place.1"}]}`)),
+			}, nil
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	const callers = 5
+	var wg sync.WaitGroup
+	responses := make([]*ForwardGeocodeResponse, callers)
+	errs := make([]error, callers)
+	geocode := func(i int) {
+		defer wg.Done()
+		responses[i], errs[i] = client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"})
+	}
+
+	wg.Add(callers)
+	go geocode(0)
+	<-started
+	for i := 1; i < callers; i++ {
+		go geocode(i)
+	}
+	// let the other callers join the in-flight call
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected 1 HTTP call, got %v", calls)
+	}
+	for i := range responses {
+		if errs[i] != nil || len(responses[i].Features) != 1 {
+			t.Errorf("caller %v: unexpected response %+v, %v", i, responses[i], errs[i])
+		}
+	}
+}
+
+func TestFlightGroupSharesErrors(t *testing.T) {
+	group := newFlightGroup()
+	failure := errors.New("connection reset")
+
+	if _, err := group.do(context.Background(), "key", func() (*http.Response, error) { return nil, failure }); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+	if len(group.calls) != 0 {
+		t.Errorf("expected the finished call to be forgotten")
+	}
+}
+
+func TestFlightGroupWaiterContext(t *testing.T) {
+	group := newFlightGroup()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	go group.do(context.Background(), "key", func() (*http.Response, error) {
+		close(started)
+		<-release
+		return nil, errors.New("connection reset")
+	})
+	<-started
+
+	// a waiter gives up with its own context, without waiting for the call
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := group.do(ctx, "key", func() (*http.Response, error) {
+		t.Errorf("expected the waiter to join the call")
+		return nil, nil
+	}); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestFlightGroupLeaderCanceled(t *testing.T) {
+	group := newFlightGroup()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := group.do(context.Background(), "key", func() (*http.Response, error) {
+			close(started)
+			<-release
+			return nil, fmt.Errorf("failed to send request. %w", context.Canceled)
+		})
+		leaderErr <- err
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	var calls int32
+	go func() {
+		response, err := group.do(context.Background(), "key", func() (*http.Response, error) {
+			atomic.AddInt32(&calls, 1)
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil
+		})
+		if err == nil && response.StatusCode != 200 {
+			err = fmt.Errorf("unexpected status %v", response.StatusCode)
+		}
+		waiter <- err
+	}()
+	// let the waiter join the call
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the leader to get its context.Canceled, got %v", err)
+	}
+	// the waiter's context is live, it sends the request itself
+	if err, n := <-waiter, atomic.LoadInt32(&calls); err != nil || n != 1 {
+		t.Errorf("expected the waiter to call again, got %v after %v calls", err, n)
+	}
+}
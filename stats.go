@@ -0,0 +1,112 @@
+package mapbox
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointStats are the latency percentiles of the last requests to an endpoint, see MapboxConfig.LatencyWindow
+type EndpointStats struct {
+	Count int // requests sent since the client was created, retries included
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+}
+
+// latencyStats keeps a ring buffer of the latest request durations per endpoint
+type latencyStats struct {
+	window int
+
+	mutex     sync.Mutex
+	endpoints map[string]*latencyRing
+}
+
+type latencyRing struct {
+	durations []time.Duration
+	next      int
+	count     int
+}
+
+func newLatencyStats(window int) *latencyStats {
+	return &latencyStats{
+		window:    window,
+		endpoints: make(map[string]*latencyRing),
+	}
+}
+
+// record adds the duration of a request to relPath
+func (s *latencyStats) record(relPath string, d time.Duration) {
+	if s == nil {
+		return
+	}
+
+	endpoint := endpointName(relPath)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ring, ok := s.endpoints[endpoint]
+	if !ok {
+		ring = &latencyRing{durations: make([]time.Duration, 0, s.window)}
+		s.endpoints[endpoint] = ring
+	}
+	if len(ring.durations) < s.window {
+		ring.durations = append(ring.durations, d)
+	} else {
+		ring.durations[ring.next] = d
+	}
+	ring.next = (ring.next + 1) % s.window
+	ring.count++
+}
+
+func (s *latencyStats) snapshot() map[string]EndpointStats {
+	if s == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := make(map[string]EndpointStats, len(s.endpoints))
+	for endpoint, ring := range s.endpoints {
+		sorted := append([]time.Duration(nil), ring.durations...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats[endpoint] = EndpointStats{
+			Count: ring.count,
+			P50:   percentile(sorted, 50),
+			P95:   percentile(sorted, 95),
+			P99:   percentile(sorted, 99),
+		}
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of the sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// endpointName returns the API of relPath up to its version, e.g. "geocoding/v5" or "search/searchbox/v1"
+func endpointName(relPath string) string {
+	segments := strings.Split(relPath, "/")
+	for i, segment := range segments {
+		if segment == v1 || segment == v5 || segment == "v2" {
+			return strings.Join(segments[:i+1], "/")
+		}
+	}
+	return segments[0]
+}
+
+// Stats returns the latency percentiles per endpoint, e.g. "geocoding/v5" or "directions/v5", over the last
+// MapboxConfig.LatencyWindow requests. Nil when the window isn't set.
+func (c *Client) Stats() map[string]EndpointStats {
+	return c.latency.snapshot()
+}
@@ -0,0 +1,57 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLatencyStats(t *testing.T) {
+	stats := newLatencyStats(100)
+	// the first 50 durations are pushed out of the window
+	for i := 1; i <= 150; i++ {
+		stats.record("directions/v5/mapbox/driving/-117.3,33.1;-117.2,32.7", time.Duration(i)*time.Millisecond)
+	}
+	stats.record("geocoding/v5/mapbox.places/carlsbad.json", 20*time.Millisecond)
+
+	snapshot := stats.snapshot()
+	expected := map[string]EndpointStats{
+		"directions/v5": {Count: 150, P50: 100 * time.Millisecond, P95: 145 * time.Millisecond, P99: 149 * time.Millisecond},
+		"geocoding/v5":  {Count: 1, P50: 20 * time.Millisecond, P95: 20 * time.Millisecond, P99: 20 * time.Millisecond},
+	}
+	if len(snapshot) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, snapshot)
+	}
+	for endpoint, e := range expected {
+		if snapshot[endpoint] != e {
+			t.Errorf("%v: expected %+v, got %+v", endpoint, e, snapshot[endpoint])
+		}
+	}
+
+	if endpoint := endpointName("search/searchbox/v1/forward"); endpoint != "search/searchbox/v1" {
+		t.Errorf("expected search/searchbox/v1, got %v", endpoint)
+	}
+}
+
+func TestClientStats(t *testing.T) {
+	client, requests := mockClient(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"type":"FeatureCollection","features":[]}`)),
+	})
+	go func() { <-requests }()
+
+	if client.Stats() != nil {
+		t.Errorf("expected no stats by default")
+	}
+
+	client.latency = newLatencyStats(10)
+	if _, err := client.ForwardGeocode(context.Background(), &ForwardGeocodeRequest{Endpoint: EndpointPlaces, SearchText: "carlsbad"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats := client.Stats()["geocoding/v5"]; stats.Count != 1 {
+		t.Errorf("expected 1 geocoding request, got %+v", stats)
+	}
+}
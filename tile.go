@@ -0,0 +1,70 @@
+package mapbox
+
+import (
+	"math"
+)
+
+// maxMercatorLatitude is the latitude of the top edge of the slippy map tiles, where Web Mercator y reaches its extent
+const maxMercatorLatitude = 85.0511287798066
+
+// TileRange is the range of slippy map tiles at Zoom, edges included. MinY is the northern row, tile y growing
+// southwards. MinX is east of MaxX when the range crosses the antimeridian.
+type TileRange struct {
+	Zoom       int
+	MinX, MinY int
+	MaxX, MaxY int
+}
+
+// SnapToTiles expands the box outwards to the edges of the slippy map tiles at zoom (0-22) it covers, e.g. to fetch
+// only whole tiles without seams in stitched static maps, and returns the range of those tiles. Latitudes are
+// clamped to the ±85.0511° Web Mercator extent.
+func (b BoundingBox) SnapToTiles(zoom int) (BoundingBox, TileRange) {
+	zoom = int(math.Max(0, math.Min(22, float64(zoom))))
+	n := b.Normalize()
+	tiles := float64(int(1) << uint(zoom))
+
+	last := int(tiles) - 1
+	clamp := func(i int) int { return int(math.Max(0, math.Min(float64(last), float64(i)))) }
+	ceilIndex := func(f float64) int { return int(math.Ceil(f)) - 1 }
+
+	r := TileRange{
+		Zoom: zoom,
+		MinX: clamp(int(math.Floor(tileX(n.Min.Lng, tiles)))),
+		MaxX: clamp(ceilIndex(tileX(n.Max.Lng, tiles))),
+		MinY: clamp(int(math.Floor(tileY(n.Max.Lat, tiles)))),
+		MaxY: clamp(ceilIndex(tileY(n.Min.Lat, tiles))),
+	}
+	if !b.CrossesAntimeridian() && r.MaxX < r.MinX {
+		r.MaxX = r.MinX
+	}
+	if r.MaxY < r.MinY {
+		r.MaxY = r.MinY
+	}
+
+	snapped := BoundingBox{
+		Min: Coordinate{Lat: tileLat(float64(r.MaxY+1), tiles), Lng: tileLng(float64(r.MinX), tiles)},
+		Max: Coordinate{Lat: tileLat(float64(r.MinY), tiles), Lng: tileLng(float64(r.MaxX+1), tiles)},
+	}
+	return snapped, r
+}
+
+// tileX returns the fractional tile column of lng among tiles columns
+func tileX(lng, tiles float64) float64 {
+	return (lng + 180) / 360 * tiles
+}
+
+// tileY returns the fractional tile row of lat among tiles rows, 0 at the north edge
+func tileY(lat, tiles float64) float64 {
+	lat = radians(math.Max(-maxMercatorLatitude, math.Min(maxMercatorLatitude, lat)))
+	return (1 - math.Log(math.Tan(lat)+1/math.Cos(lat))/math.Pi) / 2 * tiles
+}
+
+// tileLng returns the longitude of the west edge of tile column x
+func tileLng(x, tiles float64) float64 {
+	return x/tiles*360 - 180
+}
+
+// tileLat returns the latitude of the north edge of tile row y
+func tileLat(y, tiles float64) float64 {
+	return degrees(math.Atan(math.Sinh(math.Pi * (1 - 2*y/tiles))))
+}
@@ -0,0 +1,40 @@
+package mapbox
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoundingBoxSnapToTiles(t *testing.T) {
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	// Carlsbad at zoom 10 lies in the tile column 178 and rows 411-412
+	box := BoundingBox{Min: Coordinate{Lat: 33.05, Lng: -117.35}, Max: Coordinate{Lat: 33.2, Lng: -117.2}}
+	snapped, tiles := box.SnapToTiles(10)
+	if expected := (TileRange{Zoom: 10, MinX: 178, MinY: 411, MaxX: 178, MaxY: 412}); tiles != expected {
+		t.Errorf("expected %+v, got %+v", expected, tiles)
+	}
+	if !near(snapped.Min.Lng, tileLng(178, 1024)) || !near(snapped.Max.Lng, tileLng(179, 1024)) ||
+		!near(snapped.Max.Lat, tileLat(411, 1024)) || !near(snapped.Min.Lat, tileLat(413, 1024)) {
+		t.Errorf("unexpected snapped box %+v", snapped)
+	}
+	if !snapped.Contains(box.Min) || !snapped.Contains(box.Max) {
+		t.Errorf("expected %+v to contain %+v", snapped, box)
+	}
+
+	// a box on tile edges is unchanged
+	edges := BoundingBox{Min: Coordinate{Lat: 0, Lng: -180}, Max: Coordinate{Lat: maxMercatorLatitude, Lng: 0}}
+	snapped, tiles = edges.SnapToTiles(1)
+	if expected := (TileRange{Zoom: 1, MinX: 0, MinY: 0, MaxX: 0, MaxY: 0}); tiles != expected {
+		t.Errorf("expected %+v, got %+v", expected, tiles)
+	}
+	if !near(snapped.Min.Lat, 0) || !near(snapped.Max.Lat, maxMercatorLatitude) || snapped.Min.Lng != -180 || snapped.Max.Lng != 0 {
+		t.Errorf("unexpected snapped box %+v", snapped)
+	}
+
+	// the whole world at zoom 0 is one tile
+	world := BoundingBox{Min: Coordinate{Lat: -90, Lng: -180}, Max: Coordinate{Lat: 90, Lng: 180}}
+	if _, tiles := world.SnapToTiles(0); tiles != (TileRange{}) {
+		t.Errorf("expected the single zoom 0 tile, got %+v", tiles)
+	}
+}
@@ -0,0 +1,69 @@
+package mapbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	tokensPath = "tokens"
+
+	TokenValid = "TokenValid"
+)
+
+// ErrInvalidToken is returned by ValidateToken when Mapbox rejects the access token
+var ErrInvalidToken = errors.New("invalid access token")
+
+type TokenResponse struct {
+	Code  string `json:"code"`
+	Token struct {
+		Usage  string   `json:"usage"`
+		User   string   `json:"user"`
+		Scopes []string `json:"scopes,omitempty"`
+	} `json:"token"`
+}
+
+// ValidateToken checks the access token with the token retrieval endpoint, e.g. in a startup health check.
+// It returns ErrInvalidToken, wrapped with the Mapbox code, when the token is malformed, invalid, expired or
+// revoked, and a ScopeError listing the missing ones when the token lacks any of scopes. The endpoint is not billed.
+func (c *Client) ValidateToken(ctx context.Context, scopes ...string) error {
+	if err := c.checkRateLimit(TokensRateLimit); err != nil {
+		return err
+	}
+
+	// the token is sent as the access_token param, the endpoint describes the token it is called with
+	apiResponse, err := c.get(ctx, fmt.Sprintf("%v/v2", tokensPath), nil)
+	if err != nil {
+		return err
+	}
+
+	var response TokenResponse
+	if apiResponse.StatusCode == http.StatusUnauthorized {
+		apiResponse.Body.Close()
+		return fmt.Errorf("%w. rejected with status %v", ErrInvalidToken, apiResponse.StatusCode)
+	}
+	if err := c.handleResponse(apiResponse, &response, TokensRateLimit); err != nil {
+		return err
+	}
+	if response.Code != TokenValid {
+		return fmt.Errorf("%w. %v", ErrInvalidToken, response.Code)
+	}
+
+	granted := make(map[string]bool, len(response.Token.Scopes))
+	for _, scope := range response.Token.Scopes {
+		granted[scope] = true
+	}
+	var missing []string
+	for _, scope := range scopes {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) != 0 {
+		return ScopeError{Required: missing, Granted: response.Token.Scopes}
+	}
+
+	return nil
+}
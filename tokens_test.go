@@ -0,0 +1,89 @@
+package mapbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestValidateToken(t *testing.T) {
+	tests := []struct {
+		status  int
+		body    string
+		scopes  []string
+		invalid bool
+		err     bool
+	}{
+		{200, `{"code":"TokenValid","token":{"usage":"pk","user":"me","scopes":["styles:tiles","styles:read"]}}`, []string{"styles:read"}, false, false},
+		{200, `{"code":"TokenValid","token":{"usage":"pk","user":"me","scopes":["styles:tiles"]}}`, []string{"styles:read"}, false, true},
+		{200, `{"code":"TokenExpired"}`, nil, true, true},
+		{401, `{"code":"TokenInvalid"}`, nil, true, true},
+	}
+
+	for _, test := range tests {
+		client, requests := mockClient(&http.Response{
+			StatusCode: test.status,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(test.body)),
+		})
+		client.apiKey = "pk.token"
+		go func() {
+			httpReq := <-requests
+			if expected := "/tokens/v2?access_token=pk.token"; httpReq.URL.RequestURI() != expected {
+				t.Errorf("expected %q, got %q", expected, httpReq.URL.RequestURI())
+			}
+		}()
+
+		err := client.ValidateToken(context.Background(), test.scopes...)
+		if (err != nil) != test.err {
+			t.Errorf("expected error %v, got %v", test.err, err)
+		}
+		if errors.Is(err, ErrInvalidToken) != test.invalid {
+			t.Errorf("expected invalid token %v, got %v", test.invalid, err)
+		}
+	}
+}
+
+func TestScopeError(t *testing.T) {
+	client, requests := mockClient(&http.Response{
+		StatusCode: 403,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"This endpoint requires a token with tokens:write scope."}`)),
+	})
+	go func() { <-requests }()
+
+	err := client.ValidateToken(context.Background())
+	var scopeErr ScopeError
+	if !errors.As(err, &scopeErr) || !equalStrings(scopeErr.Required, []string{"tokens:write"}) {
+		t.Fatalf("expected a tokens:write scope error, got %v", err)
+	}
+	var mapboxErr MapboxError
+	if !errors.As(err, &mapboxErr) || mapboxErr.StatusCode != 403 {
+		t.Errorf("expected the 403 MapboxError, got %v", err)
+	}
+
+	// a 403 without scopes, e.g. a URL restriction, stays a MapboxError
+	client, requests = mockClient(&http.Response{
+		StatusCode: 403,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message":"Forbidden"}`)),
+	})
+	go func() { <-requests }()
+	if err := client.ValidateToken(context.Background()); errors.As(err, &scopeErr) {
+		t.Errorf("expected no scope error, got %v", err)
+	}
+
+	// missing scopes found by ValidateToken list the granted ones
+	client, requests = mockClient(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{"code":"TokenValid","token":{"usage":"pk","scopes":["styles:tiles"]}}`)),
+	})
+	go func() { <-requests }()
+	err = client.ValidateToken(context.Background(), "styles:read")
+	if !errors.As(err, &scopeErr) || !equalStrings(scopeErr.Granted, []string{"styles:tiles"}) {
+		t.Errorf("expected a scope error listing the granted scopes, got %v", err)
+	}
+	if expected := "access token lacks the scopes styles:read, it has styles:tiles"; err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err)
+	}
+}
@@ -16,10 +16,12 @@ const (
 	EndpointPlaces          = Endpoint("mapbox.places")
 	EndpointPlacesPermanent = Endpoint("mapbox.places-permanent")
 
-	AnnotationDuration   = Annotation("duration")
-	AnnotationDistance   = Annotation("distance")
-	AnnotationSpeed      = Annotation("speed")
-	AnnotationCongestion = Annotation("congestion")
+	AnnotationDuration          = Annotation("duration")
+	AnnotationDistance          = Annotation("distance")
+	AnnotationSpeed             = Annotation("speed")
+	AnnotationCongestion        = Annotation("congestion")
+	AnnotationCongestionNumeric = Annotation("congestion_numeric")
+	AnnotationMaxspeed          = Annotation("maxspeed")
 
 	ApproachUnrestricted = Approach("unrestricted")
 	ApproachCurb         = Approach("curb")
@@ -36,6 +38,12 @@ const (
 	TypeNeighborhood = Type("neighborhood")
 	TypeAddress      = Type("address")
 	TypePOI          = Type("poi")
+	// TypePOILandmark is the subset of POIs that are notable landmarks, geocoding v5 only
+	TypePOILandmark = Type("poi.landmark")
+	// TypeStreet is a street without a house number, Search Box only
+	TypeStreet = Type("street")
+	// TypeSecondaryAddress is a unit within an address, e.g. an apartment. Search Box only, geocoding v5 rejects it.
+	TypeSecondaryAddress = Type("secondary_address")
 
 	ExcludeMotorway      = Exclude("motorway")
 	ExcludeToll          = Exclude("toll")
@@ -55,11 +63,16 @@ const (
 	OverviewSimplified = Overview("simplified")
 	OverviewFalse      = Overview("false")
 
-	VoiceUnitsImpreial = VoiceUnits("imperial")
+	ETATypeNavigation = ETAType("navigation")
+
+	VoiceUnitsImperial = VoiceUnits("imperial")
 	VoiceUnitsMetric   = VoiceUnits("metric")
+	// Deprecated: use VoiceUnitsImperial
+	VoiceUnitsImpreial = VoiceUnitsImperial
 )
 
 type Profile string
+type ETAType string
 type Endpoint string
 type Geometries string
 type Overview string
@@ -141,14 +154,16 @@ func (d Destinations) query() string {
 
 //////////////////////////////////////////////////////////////////
 
+// FallbackSpeed is the speed in km/h Matrix uses to estimate the entries it can't route, from the straight-line
+// distance between the points. The estimates replace null entries, so the matrix has no gaps.
 type FallbackSpeed float64
 
 func (f FallbackSpeed) query() string {
-	if f == 0 {
+	if f <= 0 {
 		return ""
 	}
 
-	return fmt.Sprintf("%f", f)
+	return strconv.FormatFloat(float64(f), 'f', -1, 64)
 }
 
 //////////////////////////////////////////////////////////////////
@@ -164,6 +179,22 @@ func (r ReverseMode) query() string {
 type Types []Type
 type Type string
 
+// TypesAll returns every documented geocoding v5 feature type, to request them explicitly.
+// This differs from leaving Types nil, which omits the parameter and lets Mapbox apply its default set.
+func TypesAll() Types {
+	return Types{
+		TypeCountry,
+		TypeRegion,
+		TypePostcode,
+		TypeDistrict,
+		TypePlace,
+		TypeLocality,
+		TypeNeighborhood,
+		TypeAddress,
+		TypePOI,
+	}
+}
+
 func (t Types) strings() []string {
 	res := make([]string, 0, len(t))
 
@@ -178,6 +209,85 @@ func (t Types) query() string {
 	return strings.Join(t.strings(), ",")
 }
 
+// TypesAddressOnly returns the types of address autocomplete, e.g. a checkout form
+func TypesAddressOnly() Types {
+	return Types{TypeAddress}
+}
+
+// TypesAdminOnly returns the administrative and postal types, from country down to neighborhood
+func TypesAdminOnly() Types {
+	return Types{TypeCountry, TypeRegion, TypePostcode, TypeDistrict, TypePlace, TypeLocality, TypeNeighborhood}
+}
+
+// TypesPlacesAndAddresses returns the types of a general location search, addresses and the places they are in
+// without the POIs. It is a new slice callers can extend, e.g. with TypePOI.
+func TypesPlacesAndAddresses() Types {
+	return Types{TypeAddress, TypeNeighborhood, TypeLocality, TypePlace, TypePostcode}
+}
+
+// TypesForZoom returns the types to reverse geocode a click on a map at zoom, e.g. the country at world view and
+// the address at street view. The types accumulate with the zoom and Mapbox returns the most granular match:
+//
+//	0-3   country
+//	4-6   + region
+//	7-9   + district, place
+//	10-12 + locality, postcode
+//	13-15 + neighborhood, address
+//	16+   + poi
+func TypesForZoom(zoom int) Types {
+	types := Types{TypeCountry}
+	if zoom >= 4 {
+		types = append(types, TypeRegion)
+	}
+	if zoom >= 7 {
+		types = append(types, TypeDistrict, TypePlace)
+	}
+	if zoom >= 10 {
+		types = append(types, TypeLocality, TypePostcode)
+	}
+	if zoom >= 13 {
+		types = append(types, TypeNeighborhood, TypeAddress)
+	}
+	if zoom >= 16 {
+		types = append(types, TypePOI)
+	}
+	return types
+}
+
+// Validate checks the types are geocoding v5 types, each given once. Mapbox rejects unknown types, and the
+// Search Box only types with a hint to use SearchBoxForward.
+func (t Types) Validate() error {
+	seen := make(map[Type]bool, len(t))
+	for _, val := range t {
+		switch val {
+		case TypeCountry, TypeRegion, TypePostcode, TypeDistrict, TypePlace, TypeLocality, TypeNeighborhood,
+			TypeAddress, TypePOI, TypePOILandmark:
+		case TypeSecondaryAddress, TypeStreet:
+			return fmt.Errorf("type %q is not supported by geocoding, use SearchBoxForward", val)
+		default:
+			return fmt.Errorf("unknown type %q", val)
+		}
+		if seen[val] {
+			return fmt.Errorf("type %q is given more than once", val)
+		}
+		seen[val] = true
+	}
+	return nil
+}
+
+// discouraged returns a warning for valid but redundant combinations, nil otherwise
+func (t Types) discouraged() error {
+	poi, landmark := false, false
+	for _, val := range t {
+		poi = poi || val == TypePOI
+		landmark = landmark || val == TypePOILandmark
+	}
+	if poi && landmark {
+		return fmt.Errorf("type %q is redundant with %q, which includes the landmarks", TypePOILandmark, TypePOI)
+	}
+	return nil
+}
+
 //////////////////////////////////////////////////////////////////
 
 type Excludes []Exclude
@@ -221,6 +331,17 @@ func (i Includes) query() string {
 type DirectionWaypoints []DirectionWaypoint
 type DirectionWaypoint string
 
+// NewDirectionWaypoints returns the waypoints for the given coordinate indices
+func NewDirectionWaypoints(indices ...int) DirectionWaypoints {
+	res := make(DirectionWaypoints, 0, len(indices))
+
+	for _, index := range indices {
+		res = append(res, DirectionWaypoint(strconv.Itoa(index)))
+	}
+
+	return res
+}
+
 func (d DirectionWaypoints) strings() []string {
 	res := make([]string, 0, len(d))
 
@@ -292,6 +413,16 @@ func (t DepartAt) query() string {
 	return time.Time(t).UTC().Format(DepartAtFormat)
 }
 
+// MarshalJSON encodes the time as RFC 3339, or null when unset, e.g. to queue the request
+func (t DepartAt) MarshalJSON() ([]byte, error) {
+	return marshalTime(time.Time(t))
+}
+
+// UnmarshalJSON decodes an RFC 3339 time or null
+func (t *DepartAt) UnmarshalJSON(data []byte) error {
+	return unmarshalTime(data, (*time.Time)(t))
+}
+
 //////////////////////////////////////////////////////////////////
 
 type ArriveBy time.Time
@@ -311,6 +442,16 @@ func (t ArriveBy) query() string {
 	return time.Time(t).UTC().Format(ArriveByFormat)
 }
 
+// MarshalJSON encodes the time as RFC 3339, or null when unset, e.g. to queue the request
+func (t ArriveBy) MarshalJSON() ([]byte, error) {
+	return marshalTime(time.Time(t))
+}
+
+// UnmarshalJSON decodes an RFC 3339 time or null
+func (t *ArriveBy) UnmarshalJSON(data []byte) error {
+	return unmarshalTime(data, (*time.Time)(t))
+}
+
 //////////////////////////////////////////////////////////////////
 
 type DepartureTime time.Time
@@ -330,4 +471,30 @@ func (t DepartureTime) query() string {
 	return time.Time(t).UTC().Format(DepartureTimeFormat)
 }
 
+// MarshalJSON encodes the time as RFC 3339, or null when unset, e.g. to queue the request
+func (t DepartureTime) MarshalJSON() ([]byte, error) {
+	return marshalTime(time.Time(t))
+}
+
+// UnmarshalJSON decodes an RFC 3339 time or null
+func (t *DepartureTime) UnmarshalJSON(data []byte) error {
+	return unmarshalTime(data, (*time.Time)(t))
+}
+
 //////////////////////////////////////////////////////////////////
+
+// marshalTime encodes the request times, which don't inherit the time.Time JSON encoding
+func marshalTime(t time.Time) ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return t.MarshalJSON()
+}
+
+func unmarshalTime(data []byte, t *time.Time) error {
+	if string(data) == "null" {
+		*t = time.Time{}
+		return nil
+	}
+	return t.UnmarshalJSON(data)
+}
@@ -0,0 +1,144 @@
+package mapbox
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypesQuery(t *testing.T) {
+	tests := []struct {
+		types    Types
+		expected string
+	}{
+		{nil, ""},
+		{Types{}, ""},
+		{Types{TypeAddress}, "address"},
+		{Types{TypeAddress, TypePOI}, "address,poi"},
+		{TypesAll(), "country,region,postcode,district,place,locality,neighborhood,address,poi"},
+	}
+
+	for _, test := range tests {
+		if actual := test.types.query(); actual != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestTypesValidate(t *testing.T) {
+	tests := []struct {
+		types Types
+		err   bool
+	}{
+		{nil, false},
+		{Types{TypeAddress, TypePOI, TypePOILandmark}, false},
+		{Types{TypeCountry, TypeRegion, TypePostcode, TypeDistrict, TypePlace, TypeLocality, TypeNeighborhood}, false},
+		{Types{"city"}, true},
+		{Types{TypeAddress, TypeAddress}, true},
+		{Types{TypeStreet}, true},
+		{Types{TypeSecondaryAddress}, true},
+	}
+
+	for _, test := range tests {
+		if err := test.types.Validate(); (err != nil) != test.err {
+			t.Errorf("%v: expected error %v, got %v", test.types, test.err, err)
+		}
+	}
+
+	if err := (Types{TypePOI, TypePOILandmark}).discouraged(); err == nil {
+		t.Errorf("expected poi and poi.landmark to be discouraged")
+	}
+	if err := (Types{TypePOILandmark, TypeAddress}).discouraged(); err != nil {
+		t.Errorf("expected no warning, got %v", err)
+	}
+}
+
+func TestTypesPresets(t *testing.T) {
+	for _, preset := range []func() Types{TypesAddressOnly, TypesAdminOnly, TypesPlacesAndAddresses} {
+		if err := preset().Validate(); err != nil {
+			t.Errorf("expected valid preset types, got %v", err)
+		}
+	}
+
+	if query := TypesPlacesAndAddresses().query(); query != "address,neighborhood,locality,place,postcode" {
+		t.Errorf("unexpected types %v", query)
+	}
+
+	// presets are fresh slices
+	extended := append(TypesAddressOnly(), TypePOI)
+	if len(extended) != 2 || len(TypesAddressOnly()) != 1 {
+		t.Errorf("expected the preset to be left untouched, got %v", TypesAddressOnly())
+	}
+}
+
+func TestTypesForZoom(t *testing.T) {
+	tests := []struct {
+		zoom     int
+		expected string
+	}{
+		{0, "country"},
+		{5, "country,region"},
+		{8, "country,region,district,place"},
+		{12, "country,region,district,place,locality,postcode"},
+		{14, "country,region,district,place,locality,postcode,neighborhood,address"},
+		{18, "country,region,district,place,locality,postcode,neighborhood,address,poi"},
+	}
+	for _, test := range tests {
+		if types := TypesForZoom(test.zoom); types.query() != test.expected || types.Validate() != nil {
+			t.Errorf("zoom %v: expected %v, got %v", test.zoom, test.expected, types.query())
+		}
+	}
+}
+
+func TestRequestsJSONRoundTrip(t *testing.T) {
+	yes := true
+	departure := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	coordinates := Coordinates{{Lat: 33.122508, Lng: -117.306786}, {Lat: 32.733810, Lng: -117.193443}}
+
+	requests := []interface{}{
+		&ForwardGeocodeRequest{
+			Endpoint:     EndpointPlaces,
+			SearchText:   "coffee",
+			BBox:         BoundingBox{Min: Coordinate{Lat: 32.5, Lng: -117.5}, Max: Coordinate{Lat: 33.5, Lng: -116.5}},
+			Proximity:    Coordinate{Lat: 33.122508, Lng: -117.306786},
+			Types:        Types{TypePOI, TypeAddress},
+			ExcludeTypes: Types{TypeCountry},
+			Limit:        5,
+		},
+		&ReverseGeocodeRequest{Endpoint: EndpointPlaces, Coordinates: coordinates[:1], Types: Types{TypeAddress}, ReverseMode: ReverseModeScore, AddressableRadius: 50},
+		&SearchBoxForwardRequest{SearchText: "coffee", Proximity: coordinates[0], POICategories: Categories{CategoryCoffee}, ETAType: ETATypeNavigation, NavigationProfile: ProfileDriving},
+		&DirectionsRequest{
+			Profile:      ProfileDriving,
+			Coordinates:  coordinates,
+			Alternatives: &yes,
+			Annotations:  Annotations{AnnotationDuration},
+			Waypoints:    DirectionWaypoints{"0", "1"},
+			DepartAt:     DepartAt(departure),
+			MaxHeight:    4.1,
+		},
+		&DirectionsRequest{Profile: ProfileDriving, Coordinates: coordinates, ArriveBy: ArriveBy(departure)},
+		&DirectionsMatrixRequest{Profile: ProfileDriving, Coordinates: coordinates, Sources: Sources{0}, FallbackSpeed: 50, DepartureTime: DepartureTime(departure)},
+	}
+
+	for _, req := range requests {
+		data, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("failed to marshal %T: %v", req, err)
+		}
+		decoded := reflect.New(reflect.TypeOf(req).Elem()).Interface()
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatalf("failed to unmarshal %T %s: %v", req, data, err)
+		}
+		if !reflect.DeepEqual(req, decoded) {
+			t.Errorf("%T didn't round-trip through %s, got %+v", req, data, decoded)
+		}
+	}
+
+	// unset times encode as null
+	data, _ := json.Marshal(&DirectionsMatrixRequest{})
+	var matrix map[string]interface{}
+	if err := json.Unmarshal(data, &matrix); err != nil || matrix["DepartureTime"] != nil {
+		t.Errorf("expected a null DepartureTime, got %s", data)
+	}
+}
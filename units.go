@@ -0,0 +1,77 @@
+package mapbox
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type DistanceUnit string
+
+const (
+	UnitMeters     = DistanceUnit("m")
+	UnitKilometers = DistanceUnit("km")
+	UnitFeet       = DistanceUnit("ft")
+	UnitMiles      = DistanceUnit("mi")
+)
+
+// meters per unit
+var distanceUnits = map[DistanceUnit]float64{
+	UnitMeters:     1,
+	UnitKilometers: 1000,
+	UnitFeet:       0.3048,
+	UnitMiles:      1609.344,
+}
+
+// Units is the unit system of the readable route helpers, the raw response fields are always meters and seconds
+type Units string
+
+const (
+	UnitsMetric   = Units("metric")
+	UnitsImperial = Units("imperial")
+)
+
+// DistanceIn returns the route distance converted from meters to unit, NaN for an unknown unit
+func (r *Route) DistanceIn(unit DistanceUnit) float64 {
+	metersPerUnit, ok := distanceUnits[unit]
+	if !ok {
+		return math.NaN()
+	}
+	return r.Distance / metersPerUnit
+}
+
+// DistanceReadable returns the route distance in the client Units, e.g. "12.3 km" or "850 m" in metric
+// and "7.6 mi" or "500 ft" in imperial
+func (r *Route) DistanceReadable() string {
+	if r.units == UnitsImperial {
+		if miles := r.DistanceIn(UnitMiles); miles >= 0.1 {
+			return fmt.Sprintf("%.1f mi", miles)
+		}
+		return fmt.Sprintf("%.0f ft", r.DistanceIn(UnitFeet))
+	}
+
+	if r.Distance >= 1000 {
+		return fmt.Sprintf("%.1f km", r.DistanceIn(UnitKilometers))
+	}
+	return fmt.Sprintf("%.0f m", r.Distance)
+}
+
+// DurationReadable returns the route duration rounded to the minute, e.g. "1 h 5 min" or "28 min".
+// Routes shorter than a minute are reported as "< 1 min".
+func (r *Route) DurationReadable() string {
+	d := seconds(r.Duration).Round(time.Minute)
+	if d < time.Minute {
+		return "< 1 min"
+	}
+
+	hours := int(d / time.Hour)
+	minutes := int((d % time.Hour) / time.Minute)
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%d min", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%d h", hours)
+	default:
+		return fmt.Sprintf("%d h %d min", hours, minutes)
+	}
+}
@@ -0,0 +1,50 @@
+package mapbox
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRouteUnits(t *testing.T) {
+	route := &Route{Distance: 12345, Duration: 3899}
+
+	if miles := route.DistanceIn(UnitMiles); math.Abs(miles-7.6708) > 1e-4 {
+		t.Errorf("expected ~7.6708 mi, got %v", miles)
+	}
+	if km := route.DistanceIn(UnitKilometers); km != 12.345 {
+		t.Errorf("expected 12.345 km, got %v", km)
+	}
+	if !math.IsNaN(route.DistanceIn("furlong")) {
+		t.Errorf("expected NaN for an unknown unit")
+	}
+
+	if readable := route.DistanceReadable(); readable != "12.3 km" {
+		t.Errorf("unexpected metric distance %q", readable)
+	}
+	route.units = UnitsImperial
+	if readable := route.DistanceReadable(); readable != "7.7 mi" {
+		t.Errorf("unexpected imperial distance %q", readable)
+	}
+	if readable := (&Route{Distance: 100, units: UnitsImperial}).DistanceReadable(); readable != "328 ft" {
+		t.Errorf("unexpected imperial distance %q", readable)
+	}
+	if readable := (&Route{Distance: 850}).DistanceReadable(); readable != "850 m" {
+		t.Errorf("unexpected metric distance %q", readable)
+	}
+
+	tests := map[float64]string{
+		3899: "1 h 5 min",
+		3600: "1 h",
+		1680: "28 min",
+		20:   "< 1 min",
+	}
+	for duration, expected := range tests {
+		if readable := (&Route{Duration: duration}).DurationReadable(); readable != expected {
+			t.Errorf("expected %q, got %q", expected, readable)
+		}
+	}
+
+	if _, err := NewClient(&MapboxConfig{APIKey: "test", Units: "nautical"}); err == nil {
+		t.Errorf("expected unknown units error")
+	}
+}